@@ -123,6 +123,19 @@ func main() {
 
 	// Create and configure HTTP server
 	srv := multiminer.NewServer(mgr)
+	srv.SetAuthConfig(config.Security.Auth)
+
+	cfgMgr := multiminer.NewConfigManager(config, *configFile)
+	if err := cfgMgr.Subscribe(func(cfg *multiminer.Config) error {
+		multiminer.SetLogger(multiminer.NewSimpleLogger(cfg.GetLogLevel()))
+		return nil
+	}); err != nil {
+		log.Fatalf("Failed to apply initial config: %v", err)
+	}
+	if err := srv.UseConfigManager(cfgMgr); err != nil {
+		log.Fatalf("Failed to apply initial config: %v", err)
+	}
+	srv.UseMetrics(multiminer.NewMetrics(mgr, 0))
 
 	httpServer := &http.Server{
 		Addr:         config.Server.ListenAddress,
@@ -134,7 +147,7 @@ func main() {
 	// Start server in background
 	go func() {
 		multiminer.LogInfo(ctx, "Server starting", multiminer.F("address", config.Server.ListenAddress))
-		if err := srv.Start(ctx, config.Server.ListenAddress); err != nil && err != http.ErrServerClosed {
+		if err := srv.StartWithConfig(ctx, config.Server); err != nil && err != http.ErrServerClosed {
 			multiminer.LogError(ctx, "Server error", multiminer.F("error", err))
 			stop() // Signal shutdown
 		}
@@ -0,0 +1,203 @@
+package multiminer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AuthConfig configures how the Server authenticates inbound requests.
+// Any of APIKey, BasicUser, or HMACSecret may be set independently; a
+// request is admitted if it satisfies at least one configured scheme.
+// Leaving all three empty disables authentication entirely.
+type AuthConfig struct {
+	APIKey     string        `json:"api_key,omitempty"`
+	BasicUser  string        `json:"basic_user,omitempty"`
+	BasicPass  string        `json:"basic_pass,omitempty"`
+	HMACSecret string        `json:"hmac_secret,omitempty"`
+	TokenTTL   time.Duration `json:"token_ttl,omitempty"`
+}
+
+func (c AuthConfig) enabled() bool {
+	return c.APIKey != "" || c.BasicUser != "" || c.HMACSecret != ""
+}
+
+func (c AuthConfig) tokenTTL() time.Duration {
+	if c.TokenTTL > 0 {
+		return c.TokenTTL
+	}
+	return time.Hour
+}
+
+// IssueToken mints an HMAC-signed bearer token for subject, valid for
+// cfg.TokenTTL (default 1h). It fails if cfg has no HMACSecret configured.
+func IssueToken(cfg AuthConfig, subject string) (string, error) {
+	if cfg.HMACSecret == "" {
+		return "", NewInvalidInputError("no hmac secret configured")
+	}
+	exp := time.Now().Add(cfg.tokenTTL()).Unix()
+	payload := subject + "." + strconv.FormatInt(exp, 10)
+	mac := hmac.New(sha256.New, []byte(cfg.HMACSecret))
+	mac.Write([]byte(payload))
+	sig := mac.Sum(nil)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+func verifyToken(token, secret string) bool {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return false
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return false
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return false
+	}
+	idx := strings.LastIndex(string(payload), ".")
+	if idx < 0 {
+		return false
+	}
+	exp, err := strconv.ParseInt(string(payload[idx+1:]), 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() < exp
+}
+
+func secureCompare(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// csrfStore issues and validates short-lived CSRF tokens, the way
+// Syncthing's api package pairs a Set-Cookie challenge on GET with an
+// X-CSRF-Token header on state-changing requests.
+type csrfStore struct {
+	mu     sync.Mutex
+	tokens map[string]time.Time
+	ttl    time.Duration
+}
+
+func newCSRFStore() *csrfStore {
+	return &csrfStore{tokens: make(map[string]time.Time), ttl: time.Hour}
+}
+
+func (c *csrfStore) issue() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	token := hex.EncodeToString(buf)
+
+	c.mu.Lock()
+	c.tokens[token] = time.Now().Add(c.ttl)
+	c.mu.Unlock()
+	return token
+}
+
+func (c *csrfStore) valid(token string) bool {
+	if token == "" {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	exp, ok := c.tokens[token]
+	if !ok || time.Now().After(exp) {
+		delete(c.tokens, token)
+		return false
+	}
+	return true
+}
+
+// AuthMiddleware wraps the REST API with authentication and CSRF
+// protection. Its config can be swapped at runtime via SetConfig, so
+// credentials can be rotated without restarting the server.
+type AuthMiddleware struct {
+	cfg  atomic.Value // AuthConfig
+	csrf *csrfStore
+}
+
+// NewAuthMiddleware creates an AuthMiddleware. Pass a zero-value AuthConfig
+// to start with authentication disabled.
+func NewAuthMiddleware(cfg AuthConfig) *AuthMiddleware {
+	m := &AuthMiddleware{csrf: newCSRFStore()}
+	m.SetConfig(cfg)
+	return m
+}
+
+// SetConfig reloads the auth config, taking effect on the next request.
+func (m *AuthMiddleware) SetConfig(cfg AuthConfig) {
+	m.cfg.Store(cfg)
+}
+
+func (m *AuthMiddleware) config() AuthConfig {
+	v, _ := m.cfg.Load().(AuthConfig)
+	return v
+}
+
+func (m *AuthMiddleware) authenticate(r *http.Request, cfg AuthConfig) bool {
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, "Bearer ") {
+		if cfg.HMACSecret != "" && verifyToken(strings.TrimPrefix(header, "Bearer "), cfg.HMACSecret) {
+			return true
+		}
+	}
+	if cfg.APIKey != "" && secureCompare(r.Header.Get("X-API-Key"), cfg.APIKey) {
+		return true
+	}
+	if cfg.BasicUser != "" {
+		if user, pass, ok := r.BasicAuth(); ok && secureCompare(user, cfg.BasicUser) && secureCompare(pass, cfg.BasicPass) {
+			return true
+		}
+	}
+	return false
+}
+
+// Wrap returns an http.Handler that authenticates requests against the
+// current AuthConfig before delegating to next. GET/HEAD responses set a
+// csrf_token cookie; every other method must echo a token this middleware
+// issued, unexpired, in the X-CSRF-Token header.
+func (m *AuthMiddleware) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cfg := m.config()
+		if !cfg.enabled() {
+			next(w, r)
+			return
+		}
+
+		if !m.authenticate(r, cfg) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="multiminer"`)
+			writeMultiMinerError(w, NewUnauthorizedError("missing or invalid credentials"))
+			return
+		}
+
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			http.SetCookie(w, &http.Cookie{
+				Name:     "csrf_token",
+				Value:    m.csrf.issue(),
+				Path:     "/",
+				SameSite: http.SameSiteStrictMode,
+			})
+		} else {
+			if !m.csrf.valid(r.Header.Get("X-CSRF-Token")) {
+				writeMultiMinerError(w, NewForbiddenError("missing or invalid csrf token"))
+				return
+			}
+		}
+
+		next(w, r)
+	}
+}
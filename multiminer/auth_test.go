@@ -0,0 +1,51 @@
+package multiminer
+
+import "testing"
+
+func TestIssueAndVerifyToken(t *testing.T) {
+	cfg := AuthConfig{HMACSecret: "test-secret"}
+
+	token, err := IssueToken(cfg, "alice")
+	if err != nil {
+		t.Fatalf("IssueToken: %v", err)
+	}
+	if !verifyToken(token, cfg.HMACSecret) {
+		t.Errorf("expected token to verify")
+	}
+	if verifyToken(token, "wrong-secret") {
+		t.Errorf("expected token to fail verification with wrong secret")
+	}
+	if verifyToken("garbage", cfg.HMACSecret) {
+		t.Errorf("expected malformed token to fail verification")
+	}
+}
+
+func TestIssueTokenRequiresSecret(t *testing.T) {
+	if _, err := IssueToken(AuthConfig{}, "alice"); err == nil {
+		t.Errorf("expected error issuing token without an hmac secret")
+	}
+}
+
+func TestCSRFStore(t *testing.T) {
+	store := newCSRFStore()
+	token := store.issue()
+
+	if !store.valid(token) {
+		t.Errorf("expected freshly issued token to be valid")
+	}
+	if store.valid("nonexistent") {
+		t.Errorf("expected unknown token to be invalid")
+	}
+	if !store.valid(token) {
+		t.Errorf("expected token to remain valid for repeated use until it expires")
+	}
+}
+
+func TestAuthConfigEnabled(t *testing.T) {
+	if (AuthConfig{}).enabled() {
+		t.Errorf("expected zero-value AuthConfig to be disabled")
+	}
+	if !(AuthConfig{APIKey: "k"}).enabled() {
+		t.Errorf("expected AuthConfig with an APIKey to be enabled")
+	}
+}
@@ -0,0 +1,54 @@
+package multiminer
+
+import (
+	"context"
+)
+
+// DeviceSelector picks the devices a bulk operation targets: the union of
+// explicit IDs and every known device whose driver matches Driver. An empty
+// selector (no IDs, no Driver) matches no devices.
+type DeviceSelector struct {
+	IDs    []MinerID `json:"ids,omitempty"`
+	Driver string    `json:"driver,omitempty"`
+}
+
+// Resolve returns the known devices matching sel, deduplicated. IDs that
+// don't correspond to a tracked device are silently dropped; a group
+// member the manager no longer tracks, by contrast, gets an error result
+// from WithGroupSessions.
+func (m *Manager) Resolve(sel DeviceSelector) []MinerID {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	seen := make(map[MinerID]struct{})
+	var out []MinerID
+	add := func(id MinerID) {
+		if _, ok := seen[id]; ok {
+			return
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+
+	for _, id := range sel.IDs {
+		if _, ok := m.dev[id]; ok {
+			add(id)
+		}
+	}
+	if sel.Driver != "" {
+		for id, d := range m.dev {
+			if d.DriverName == sel.Driver {
+				add(id)
+			}
+		}
+	}
+	return out
+}
+
+// WithSelectedSessions fans fn out across every device matched by sel,
+// bounded by opts.Parallelism, and reports per-device results. It's the
+// selector-driven counterpart to WithGroupSessions, used by bulk REST
+// actions that target an ad-hoc set of devices instead of a named group.
+func (m *Manager) WithSelectedSessions(ctx context.Context, sel DeviceSelector, fn func(MinerID, Session) error, opts FanOutOptions) GroupResult {
+	return m.fanOut(ctx, m.Resolve(sel), fn, opts)
+}
@@ -0,0 +1,223 @@
+package multiminer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// defaultBulkPerDeviceTimeout bounds how long a single device's operation may
+// run as part of a bulk action, so one unresponsive rig can't stall the
+// whole fleet request.
+const defaultBulkPerDeviceTimeout = 10 * time.Second
+
+// bulkActionRequest is the body of POST /devices/actions.
+type bulkActionRequest struct {
+	Selector DeviceSelector  `json:"selector"`
+	Action   string          `json:"action"`
+	Payload  json.RawMessage `json:"payload,omitempty"`
+}
+
+// bulkActionResult is one device's outcome in a bulk action response.
+type bulkActionResult struct {
+	Status string           `json:"status"` // "ok" or "error"
+	Data   interface{}      `json:"data,omitempty"`
+	Error  *MultiMinerError `json:"error,omitempty"`
+}
+
+// bulkStreamEntry is one line of the chunked newline-delimited JSON variant.
+type bulkStreamEntry struct {
+	ID string `json:"id"`
+	bulkActionResult
+}
+
+// handleBulkActions serves POST /devices/actions: it runs req.Action against
+// every device matched by req.Selector, concurrently, and reports a
+// per-device result. Pass ?stream=1 to get results as newline-delimited
+// JSON as each device finishes, instead of waiting for the whole fleet.
+func (s *Server) handleBulkActions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeErrJSON(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req bulkActionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeMultiMinerError(w, NewInvalidInputError("invalid json"))
+		return
+	}
+	if req.Action == "" {
+		writeMultiMinerError(w, NewInvalidInputError("action is required"))
+		return
+	}
+
+	opts := FanOutOptions{
+		Parallelism:      s.bulkConcurrency(),
+		PerDeviceTimeout: defaultBulkPerDeviceTimeout,
+	}
+
+	if r.URL.Query().Get("stream") == "1" {
+		s.streamBulkActions(w, r, req, opts)
+		return
+	}
+
+	results := make(map[MinerID]bulkActionResult)
+	var mu sync.Mutex
+	s.mgr.WithSelectedSessions(r.Context(), req.Selector, func(id MinerID, sess Session) error {
+		data, err := s.runBulkAction(r.Context(), id, sess, req)
+		mu.Lock()
+		results[id] = bulkResultFrom(data, err)
+		mu.Unlock()
+		return err
+	}, opts)
+
+	writeJSON(w, http.StatusOK, results)
+}
+
+// streamBulkActions is the chunked newline-delimited JSON variant of
+// handleBulkActions: each device's result is written and flushed as soon as
+// it completes, rather than buffered until the whole fleet finishes.
+func (s *Server) streamBulkActions(w http.ResponseWriter, r *http.Request, req bulkActionRequest, opts FanOutOptions) {
+	ids := s.mgr.Resolve(req.Selector)
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 || parallelism > len(ids) {
+		parallelism = len(ids)
+	}
+	if parallelism == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var writeMu sync.Mutex
+	enc := json.NewEncoder(w)
+
+	for _, id := range ids {
+		id := id
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			devCtx := r.Context()
+			if opts.PerDeviceTimeout > 0 {
+				var cancel context.CancelFunc
+				devCtx, cancel = context.WithTimeout(devCtx, opts.PerDeviceTimeout)
+				defer cancel()
+			}
+
+			var data interface{}
+			err := s.mgr.WithSession(devCtx, id, func(sess Session) error {
+				var runErr error
+				data, runErr = s.runBulkAction(devCtx, id, sess, req)
+				return runErr
+			})
+
+			writeMu.Lock()
+			_ = enc.Encode(bulkStreamEntry{ID: string(id), bulkActionResult: bulkResultFrom(data, err)})
+			if flusher != nil {
+				flusher.Flush()
+			}
+			writeMu.Unlock()
+		}()
+	}
+	wg.Wait()
+}
+
+// runBulkAction dispatches req.Action against a single device's session,
+// returning the data to report on success.
+func (s *Server) runBulkAction(ctx context.Context, id MinerID, sess Session, req bulkActionRequest) (interface{}, error) {
+	switch req.Action {
+	case "summary":
+		return sess.Summary(ctx)
+	case "stats":
+		return sess.Stats(ctx)
+	case "exec":
+		var p struct {
+			Command   string `json:"command"`
+			Parameter string `json:"parameter"`
+		}
+		if err := json.Unmarshal(req.Payload, &p); err != nil {
+			return nil, NewInvalidInputError("invalid exec payload")
+		}
+		if err := s.commandValidator.ValidateCommand(p.Command); err != nil {
+			s.recordRejected("command")
+			return nil, err
+		}
+		if _, err := s.commandValidator.ValidateParameter(p.Command, p.Parameter); err != nil {
+			s.recordRejected("parameter")
+			return nil, err
+		}
+		data, err := sess.Exec(ctx, p.Command, p.Parameter)
+		s.recordExec(p.Command, execResultLabel(err))
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(data), nil
+	case "power":
+		var pm PowerMode
+		if err := json.Unmarshal(req.Payload, &pm); err != nil {
+			return nil, NewInvalidInputError("invalid power payload")
+		}
+		return nil, sess.SetPowerMode(ctx, pm)
+	case "fan":
+		var fc FanConfig
+		if err := json.Unmarshal(req.Payload, &fc); err != nil {
+			return nil, NewInvalidInputError("invalid fan payload")
+		}
+		return nil, sess.SetFan(ctx, fc)
+	case "reboot":
+		if err := sess.Restart(ctx); err != nil {
+			return nil, err
+		}
+		s.mgr.MarkRestarted(id)
+		return nil, nil
+	default:
+		return nil, NewInvalidInputError("unknown bulk action: " + req.Action)
+	}
+}
+
+func execResultLabel(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// bulkResultFrom builds a bulkActionResult from a single device's outcome.
+func bulkResultFrom(data interface{}, err error) bulkActionResult {
+	if err == nil {
+		return bulkActionResult{Status: "ok", Data: data}
+	}
+	if mErr, ok := IsMultiMinerError(err); ok {
+		return bulkActionResult{Status: "error", Error: mErr}
+	}
+	return bulkActionResult{Status: "error", Error: WrapError(err, ErrCodeDeviceError, err.Error())}
+}
+
+// bulkConcurrency returns the configured worker-pool size for bulk actions,
+// falling back to a sane default when unconfigured.
+func (s *Server) bulkConcurrency() int {
+	s.bulkMu.RLock()
+	defer s.bulkMu.RUnlock()
+	if s.bulkMaxConcurrent <= 0 {
+		return 20
+	}
+	return s.bulkMaxConcurrent
+}
+
+// SetBulkConcurrency sets the worker-pool size future bulk actions use. n
+// <= 0 resets to the default.
+func (s *Server) SetBulkConcurrency(n int) {
+	s.bulkMu.Lock()
+	defer s.bulkMu.Unlock()
+	s.bulkMaxConcurrent = n
+}
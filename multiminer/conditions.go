@@ -0,0 +1,234 @@
+package multiminer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConditionSeverity classifies how serious a Condition is, mirroring the
+// Cluster API convention of pairing a tri-state Status with a severity so
+// callers can distinguish "False and fine" from "False and on fire".
+type ConditionSeverity string
+
+const (
+	SeverityInfo    ConditionSeverity = "Info"
+	SeverityWarning ConditionSeverity = "Warning"
+	SeverityError   ConditionSeverity = "Error"
+)
+
+// ConditionStatus is the tri-state value of a Condition.
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+// ConditionType names a specific aspect of device health being tracked.
+type ConditionType string
+
+const (
+	DeviceReady     ConditionType = "DeviceReady"
+	DeviceReachable ConditionType = "DeviceReachable"
+	StatsAvailable  ConditionType = "StatsAvailable"
+	PoolsConfigured ConditionType = "PoolsConfigured"
+	PermanentError  ConditionType = "PermanentError"
+	// CircuitBreaker tracks the connection pool's per-device breaker state,
+	// letting UI consumers distinguish "temporarily flaky" (open, will
+	// retry after backoff) from "this driver fundamentally can't talk to
+	// this box" (permanently tripped, needs an operator reset).
+	CircuitBreaker ConditionType = "CircuitBreaker"
+)
+
+// Common condition reasons devices transition through.
+const (
+	ReasonDeviceNotFound    = "DeviceNotFound"
+	ReasonDetected          = "Detected"
+	ReasonSessionOpenFailed = "SessionOpenFailed"
+	ReasonSessionOK         = "SessionOK"
+	ReasonStatsFailed       = "StatsFailed"
+	ReasonStatsOK           = "StatsOK"
+	ReasonNotImplemented    = "NotImplemented"
+	ReasonReloadingTooLong  = "ReloadingTooLong"
+	ReasonCircuitClosed     = "CircuitClosed"
+	ReasonCircuitOpen       = "CircuitOpen"
+	ReasonCircuitHalfOpen   = "CircuitHalfOpen"
+	ReasonPermanentFailure  = "PermanentFailure"
+)
+
+// Condition is a single, reason-coded observation about one aspect of a
+// device's health. A Device carries a slice of these, one per ConditionType
+// last observed for it.
+type Condition struct {
+	Type               ConditionType     `json:"type"`
+	Status             ConditionStatus   `json:"status"`
+	Severity           ConditionSeverity `json:"severity,omitempty"`
+	Reason             string            `json:"reason,omitempty"`
+	Message            string            `json:"message,omitempty"`
+	LastTransitionTime time.Time         `json:"lastTransitionTime"`
+}
+
+// ConditionEvent is published to Manager.Subscribe() subscribers whenever a
+// device's condition changes.
+type ConditionEvent struct {
+	DeviceID  MinerID   `json:"device_id"`
+	Condition Condition `json:"condition"`
+}
+
+// condSubBuffer bounds how far a condition subscriber can lag before new
+// events are dropped for it, same drop-on-overflow policy as the bounded
+// HTTP log queue.
+const condSubBuffer = 64
+
+// Conditions returns a copy of the current conditions known for id, in no
+// particular order. An untracked or condition-less device returns an empty,
+// non-nil slice.
+func (m *Manager) Conditions(id MinerID) []Condition {
+	m.condMu.RLock()
+	defer m.condMu.RUnlock()
+	existing := m.conditions[id]
+	out := make([]Condition, len(existing))
+	copy(out, existing)
+	return out
+}
+
+// Subscribe registers for condition-change events across every device. The
+// returned channel is closed, and further sends stop, once the returned
+// unsubscribe func is called.
+func (m *Manager) Subscribe() (<-chan ConditionEvent, func()) {
+	ch := make(chan ConditionEvent, condSubBuffer)
+
+	m.condSubsMu.Lock()
+	m.condSubs[ch] = struct{}{}
+	m.condSubsMu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			m.condSubsMu.Lock()
+			delete(m.condSubs, ch)
+			m.condSubsMu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// setCondition upserts the condition of type c.Type for id. LastTransitionTime
+// is preserved unless Status actually changed; subscribers are only
+// notified when something about the condition (Status, Reason, or Message)
+// is different from what was last recorded.
+func (m *Manager) setCondition(id MinerID, c Condition) {
+	m.condMu.Lock()
+	existing := m.conditions[id]
+	found := false
+	changed := false
+	for i, prev := range existing {
+		if prev.Type != c.Type {
+			continue
+		}
+		found = true
+		if prev.Status == c.Status {
+			c.LastTransitionTime = prev.LastTransitionTime
+		} else {
+			if c.LastTransitionTime.IsZero() {
+				c.LastTransitionTime = time.Now()
+			}
+			changed = true
+		}
+		if prev.Reason != c.Reason || prev.Message != c.Message {
+			changed = true
+		}
+		existing[i] = c
+		break
+	}
+	if !found {
+		changed = true
+		if c.LastTransitionTime.IsZero() {
+			c.LastTransitionTime = time.Now()
+		}
+		existing = append(existing, c)
+	}
+	m.conditions[id] = existing
+	m.condMu.Unlock()
+
+	if changed {
+		m.publishCondition(id, c)
+	}
+}
+
+func (m *Manager) publishCondition(id MinerID, c Condition) {
+	ev := ConditionEvent{DeviceID: id, Condition: c}
+
+	m.condSubsMu.RLock()
+	defer m.condSubsMu.RUnlock()
+	for ch := range m.condSubs {
+		select {
+		case ch <- ev:
+		default:
+			LogWarn(context.Background(), "dropping condition event for slow subscriber",
+				F("id", string(id)), F("type", string(c.Type)))
+		}
+	}
+}
+
+// MarkRestarted records that a restart was just issued to id, so the health
+// probe can tell an expected post-restart Stats blip from a device stuck
+// reloading past its configured threshold.
+func (m *Manager) MarkRestarted(id MinerID) {
+	m.restartMu.Lock()
+	m.restartedAt[id] = time.Now()
+	m.restartMu.Unlock()
+}
+
+func (m *Manager) restartedAtOrZero(id MinerID) time.Time {
+	m.restartMu.RLock()
+	defer m.restartMu.RUnlock()
+	return m.restartedAt[id]
+}
+
+// StartHealthProbe periodically polls Stats for every tracked device and
+// updates its StatsAvailable condition. A device that keeps failing Stats
+// for longer than reloadingTooLong after a MarkRestarted call gets
+// Reason=ReloadingTooLong instead of the generic ReasonStatsFailed, so
+// alerting can distinguish "still rebooting" from "actually stuck".
+func (m *Manager) StartHealthProbe(ctx context.Context, interval, reloadingTooLong time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.probeHealthOnce(ctx, reloadingTooLong)
+			}
+		}
+	}()
+}
+
+func (m *Manager) probeHealthOnce(ctx context.Context, reloadingTooLong time.Duration) {
+	for _, dev := range m.List() {
+		id := dev.ID
+		err := m.WithSession(ctx, id, func(sess Session) error {
+			_, statsErr := sess.Stats(ctx)
+			return statsErr
+		})
+		if err != nil {
+			reason := ReasonStatsFailed
+			if restartedAt := m.restartedAtOrZero(id); !restartedAt.IsZero() && time.Since(restartedAt) > reloadingTooLong {
+				reason = ReasonReloadingTooLong
+			}
+			m.setCondition(id, Condition{
+				Type: StatsAvailable, Status: ConditionFalse, Severity: SeverityWarning,
+				Reason: reason, Message: err.Error(),
+			})
+			continue
+		}
+		m.setCondition(id, Condition{
+			Type: StatsAvailable, Status: ConditionTrue, Severity: SeverityInfo, Reason: ReasonStatsOK,
+		})
+	}
+}
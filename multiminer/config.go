@@ -1,6 +1,7 @@
 package multiminer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -24,6 +25,16 @@ type ServerConfig struct {
 	ReadTimeout   time.Duration `json:"read_timeout"`
 	WriteTimeout  time.Duration `json:"write_timeout"`
 	IdleTimeout   time.Duration `json:"idle_timeout"`
+
+	// UnixSocket, if set, additionally serves the same API over a Unix
+	// domain socket at this path — useful on hosts with tight firewall
+	// rules, or to gate access with filesystem permissions.
+	UnixSocket string `json:"unix_socket,omitempty"`
+
+	// MaxConcurrentBulk bounds how many devices a single POST
+	// /devices/actions request operates on at once, so a fleet-wide bulk
+	// action doesn't open hundreds of simultaneous driver sessions.
+	MaxConcurrentBulk int `json:"max_concurrent_bulk,omitempty"`
 }
 
 // ManagerConfig configures the device manager
@@ -31,6 +42,13 @@ type ManagerConfig struct {
 	ProbeTimeout    time.Duration `json:"probe_timeout"`
 	CleanupInterval time.Duration `json:"cleanup_interval"`
 	AutoCleanup     bool          `json:"auto_cleanup"`
+
+	// StatePath is the path to an embedded KV database used to persist
+	// registered devices across restarts. Leave empty to keep devices in-memory only.
+	StatePath string `json:"state_path"`
+	// RevalidateOnStart re-runs driver detection for each persisted device on
+	// startup, evicting entries whose driver no longer matches.
+	RevalidateOnStart bool `json:"revalidate_on_start"`
 }
 
 // PoolConfig configures connection pooling
@@ -42,17 +60,31 @@ type PoolConfig struct {
 
 // LoggingConfig configures logging
 type LoggingConfig struct {
-	Level      string `json:"level"`
-	Format     string `json:"format"`
-	OutputFile string `json:"output_file"`
+	Level      string     `json:"level"`
+	Format     string     `json:"format"`
+	OutputFile string     `json:"output_file"`
+	HTTPTarget HTTPTarget `json:"http_target"`
+}
+
+// HTTPTarget configures shipping log records to a remote HTTP endpoint.
+type HTTPTarget struct {
+	Enabled    bool              `json:"enabled"`
+	URL        string            `json:"url"`
+	BatchSize  int               `json:"batch_size"`
+	MaxWorkers int               `json:"max_workers"`
+	QueueSize  int               `json:"queue_size"`
+	Timeout    time.Duration     `json:"timeout"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	AuthBearer string            `json:"auth_bearer,omitempty"`
 }
 
 // SecurityConfig configures security settings
 type SecurityConfig struct {
-	EnableValidation bool     `json:"enable_validation"`
-	AllowedPorts     []int    `json:"allowed_ports"`
-	AllowedCommands  []string `json:"allowed_commands"`
-	RateLimitRPS     int      `json:"rate_limit_rps"`
+	EnableValidation bool       `json:"enable_validation"`
+	AllowedPorts     []int      `json:"allowed_ports"`
+	AllowedCommands  []string   `json:"allowed_commands"`
+	RateLimitRPS     int        `json:"rate_limit_rps"`
+	Auth             AuthConfig `json:"auth"`
 }
 
 // ValidationConfig configures input validation
@@ -65,17 +97,20 @@ type ValidationConfig struct {
 
 // ManagerOptions holds legacy options for backward compatibility
 type ManagerOptions struct {
-	ProbeTimeout time.Duration
+	ProbeTimeout      time.Duration
+	Store             PersistentStore
+	RevalidateOnStart bool
 }
 
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		Server: ServerConfig{
-			ListenAddress: ":8080",
-			ReadTimeout:   30 * time.Second,
-			WriteTimeout:  30 * time.Second,
-			IdleTimeout:   60 * time.Second,
+			ListenAddress:     ":8080",
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       60 * time.Second,
+			MaxConcurrentBulk: 20,
 		},
 		Manager: ManagerConfig{
 			ProbeTimeout:    1200 * time.Millisecond,
@@ -91,6 +126,12 @@ func DefaultConfig() *Config {
 			Level:      "info",
 			Format:     "text",
 			OutputFile: "",
+			HTTPTarget: HTTPTarget{
+				BatchSize:  50,
+				MaxWorkers: 4,
+				QueueSize:  1000,
+				Timeout:    5 * time.Second,
+			},
 		},
 		Security: SecurityConfig{
 			EnableValidation: true,
@@ -185,25 +226,27 @@ func (c *Config) SaveConfig(filename string) error {
 
 // ToManagerOptions converts Config to legacy ManagerOptions
 func (c *Config) ToManagerOptions() ManagerOptions {
-	return ManagerOptions{
-		ProbeTimeout: c.Manager.ProbeTimeout,
+	opt := ManagerOptions{
+		ProbeTimeout:      c.Manager.ProbeTimeout,
+		RevalidateOnStart: c.Manager.RevalidateOnStart,
 	}
+
+	if c.Manager.StatePath != "" {
+		store, err := NewBoltStore(c.Manager.StatePath)
+		if err != nil {
+			LogWarn(context.Background(), "failed to open device state store, continuing without persistence",
+				F("path", c.Manager.StatePath), F("error", err))
+		} else {
+			opt.Store = store
+		}
+	}
+
+	return opt
 }
 
 // GetLogLevel converts string log level to LogLevel
 func (c *Config) GetLogLevel() LogLevel {
-	switch c.Logging.Level {
-	case "debug":
-		return LogLevelDebug
-	case "info":
-		return LogLevelInfo
-	case "warn", "warning":
-		return LogLevelWarn
-	case "error":
-		return LogLevelError
-	default:
-		return LogLevelInfo
-	}
+	return logLevelFromString(c.Logging.Level)
 }
 
 // Legacy function for backward compatibility
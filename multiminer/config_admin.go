@@ -0,0 +1,211 @@
+package multiminer
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxConfigHistory bounds how many previously-applied configs ConfigManager
+// keeps around for revert.
+const maxConfigHistory = 20
+
+// ConfigSubscriber is notified with a candidate Config before it's committed.
+// Returning an error rejects the candidate and rolls every subscriber back
+// to the previous config.
+type ConfigSubscriber func(*Config) error
+
+// ConfigHistoryEntry is a previously-applied config, kept for revert.
+type ConfigHistoryEntry struct {
+	ID        int       `json:"id"`
+	AppliedAt time.Time `json:"applied_at"`
+	Config    *Config   `json:"config"`
+}
+
+// ConfigManager serializes config reloads behind a mutex, validates each
+// candidate, applies it to every subscriber, and atomically reverts to the
+// previous config if any subscriber rejects it. Successful changes are
+// persisted to disk and pushed onto a bounded history for revert.
+type ConfigManager struct {
+	mu          sync.Mutex
+	path        string
+	current     *Config
+	subscribers []ConfigSubscriber
+	history     []ConfigHistoryEntry
+	nextID      int
+}
+
+// NewConfigManager creates a ConfigManager seeded with cfg. path, if
+// non-empty, is where successful changes are persisted via SaveConfig.
+func NewConfigManager(cfg *Config, path string) *ConfigManager {
+	return &ConfigManager{current: cfg, path: path}
+}
+
+// Subscribe registers fn to be notified of future config changes. fn is also
+// invoked once immediately with the current config, so callers don't need a
+// separate "apply initial config" step.
+func (cm *ConfigManager) Subscribe(fn ConfigSubscriber) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if err := fn(cm.current); err != nil {
+		return err
+	}
+	cm.subscribers = append(cm.subscribers, fn)
+	return nil
+}
+
+// Current returns the currently effective config.
+func (cm *ConfigManager) Current() *Config {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	return cm.current
+}
+
+// Replace validates candidate, applies it to every subscriber in order, and
+// commits it as the current config. If any subscriber returns an error, all
+// subscribers are re-invoked with the previous config and the candidate is
+// rejected — so a config never takes effect for some subscribers but not
+// others.
+func (cm *ConfigManager) Replace(candidate *Config) error {
+	if err := validateConfig(candidate); err != nil {
+		return err
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	prev := cm.current
+	for _, sub := range cm.subscribers {
+		if err := sub(candidate); err != nil {
+			for _, rollback := range cm.subscribers {
+				_ = rollback(prev)
+			}
+			return NewInvalidInputError("config rejected: " + err.Error())
+		}
+	}
+
+	cm.pushHistoryLocked(prev)
+	cm.current = candidate
+
+	if cm.path != "" {
+		if err := candidate.SaveConfig(cm.path); err != nil {
+			LogWarn(context.Background(), "failed to persist config", F("path", cm.path), F("error", err))
+		}
+	}
+
+	return nil
+}
+
+// PatchPath sets a single field of the current config, addressed by a
+// slash-separated path of its JSON tags (e.g. "logging/level",
+// "security/allowed_ports"), to value, then runs it through Replace.
+func (cm *ConfigManager) PatchPath(path string, value json.RawMessage) error {
+	cm.mu.Lock()
+	base := cm.current
+	cm.mu.Unlock()
+
+	data, err := json.Marshal(base)
+	if err != nil {
+		return WrapError(err, ErrCodeInternalError, "failed to marshal current config")
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return WrapError(err, ErrCodeInternalError, "failed to decode current config")
+	}
+
+	var decodedValue interface{}
+	if err := json.Unmarshal(value, &decodedValue); err != nil {
+		return NewInvalidInputError("invalid json value")
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) == 0 || segments[0] == "" {
+		return NewInvalidInputError("config path must not be empty")
+	}
+	if err := setPath(tree, segments, decodedValue); err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(tree)
+	if err != nil {
+		return WrapError(err, ErrCodeInternalError, "failed to re-encode config")
+	}
+	candidate := &Config{}
+	if err := json.Unmarshal(merged, candidate); err != nil {
+		return NewInvalidInputError("patched config no longer matches the config schema")
+	}
+
+	return cm.Replace(candidate)
+}
+
+// setPath descends tree along segments and assigns value at the final one.
+func setPath(tree map[string]interface{}, segments []string, value interface{}) error {
+	if len(segments) == 1 {
+		tree[segments[0]] = value
+		return nil
+	}
+
+	child, ok := tree[segments[0]].(map[string]interface{})
+	if !ok {
+		return NewInvalidInputError("unknown config path segment: " + segments[0])
+	}
+	return setPath(child, segments[1:], value)
+}
+
+// Revert re-applies a config from history by its ID through the normal
+// Replace pipeline (so subscribers and persistence run as usual).
+func (cm *ConfigManager) Revert(id int) error {
+	cm.mu.Lock()
+	var target *Config
+	for _, h := range cm.history {
+		if h.ID == id {
+			target = h.Config
+			break
+		}
+	}
+	cm.mu.Unlock()
+
+	if target == nil {
+		return NewNotFoundError("no config history entry with that id")
+	}
+	return cm.Replace(target)
+}
+
+// History returns the bounded list of previously-applied configs, oldest first.
+func (cm *ConfigManager) History() []ConfigHistoryEntry {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	out := make([]ConfigHistoryEntry, len(cm.history))
+	copy(out, cm.history)
+	return out
+}
+
+func (cm *ConfigManager) pushHistoryLocked(cfg *Config) {
+	cm.nextID++
+	cm.history = append(cm.history, ConfigHistoryEntry{ID: cm.nextID, AppliedAt: time.Now(), Config: cfg})
+	if len(cm.history) > maxConfigHistory {
+		cm.history = cm.history[len(cm.history)-maxConfigHistory:]
+	}
+}
+
+// validateConfig sanity-checks a candidate config before it's allowed to
+// replace the current one.
+func validateConfig(cfg *Config) error {
+	if cfg == nil {
+		return NewInvalidInputError("config cannot be nil")
+	}
+	if cfg.Server.ListenAddress == "" && cfg.Server.UnixSocket == "" {
+		return NewInvalidInputError("server must have a listen address or unix socket")
+	}
+	switch cfg.Logging.Level {
+	case "", "debug", "info", "warn", "warning", "error":
+	default:
+		return NewInvalidInputError("unknown logging level: " + cfg.Logging.Level)
+	}
+	if cfg.Pool.MaxOpenConnections < cfg.Pool.MaxIdleConnections {
+		return NewInvalidInputError("pool max_open_connections cannot be less than max_idle_connections")
+	}
+	return nil
+}
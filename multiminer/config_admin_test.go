@@ -0,0 +1,101 @@
+package multiminer
+
+import "testing"
+
+func TestConfigManagerReplaceAndRevert(t *testing.T) {
+	cfg := DefaultConfig()
+	cm := NewConfigManager(cfg, "")
+
+	var seenLevel string
+	if err := cm.Subscribe(func(c *Config) error {
+		seenLevel = c.Logging.Level
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if seenLevel != "info" {
+		t.Fatalf("expected subscriber to see initial config, got %q", seenLevel)
+	}
+
+	next := DefaultConfig()
+	next.Logging.Level = "debug"
+	if err := cm.Replace(next); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if seenLevel != "debug" {
+		t.Fatalf("expected subscriber to observe new config, got %q", seenLevel)
+	}
+	if cm.Current().Logging.Level != "debug" {
+		t.Fatalf("expected current config to be updated")
+	}
+
+	history := cm.History()
+	if len(history) != 1 {
+		t.Fatalf("expected 1 history entry, got %d", len(history))
+	}
+	if err := cm.Revert(history[0].ID); err != nil {
+		t.Fatalf("Revert: %v", err)
+	}
+	if cm.Current().Logging.Level != "info" {
+		t.Fatalf("expected revert to restore prior config, got %q", cm.Current().Logging.Level)
+	}
+}
+
+func TestConfigManagerRejectsInvalidCandidate(t *testing.T) {
+	cfg := DefaultConfig()
+	cm := NewConfigManager(cfg, "")
+
+	bad := DefaultConfig()
+	bad.Logging.Level = "not-a-level"
+	if err := cm.Replace(bad); err == nil {
+		t.Fatalf("expected Replace to reject an invalid config")
+	}
+	if cm.Current().Logging.Level != "info" {
+		t.Fatalf("expected current config to be unchanged after rejection")
+	}
+}
+
+func TestConfigManagerSubscriberFailureRollsBack(t *testing.T) {
+	cfg := DefaultConfig()
+	cm := NewConfigManager(cfg, "")
+
+	applied := []string{}
+	if err := cm.Subscribe(func(c *Config) error {
+		applied = append(applied, c.Logging.Level)
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+	if err := cm.Subscribe(func(c *Config) error {
+		if c.Logging.Level == "debug" {
+			return NewInvalidInputError("rejected")
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	next := DefaultConfig()
+	next.Logging.Level = "debug"
+	if err := cm.Replace(next); err == nil {
+		t.Fatalf("expected Replace to fail when a subscriber rejects the candidate")
+	}
+	if cm.Current().Logging.Level != "info" {
+		t.Fatalf("expected current config to be unchanged after subscriber rejection")
+	}
+	if last := applied[len(applied)-1]; last != "info" {
+		t.Fatalf("expected first subscriber to be rolled back to the prior config, got %q", last)
+	}
+}
+
+func TestConfigManagerPatchPath(t *testing.T) {
+	cfg := DefaultConfig()
+	cm := NewConfigManager(cfg, "")
+
+	if err := cm.PatchPath("logging/level", []byte(`"warn"`)); err != nil {
+		t.Fatalf("PatchPath: %v", err)
+	}
+	if cm.Current().Logging.Level != "warn" {
+		t.Fatalf("expected patched logging level to be warn, got %q", cm.Current().Logging.Level)
+	}
+}
@@ -17,27 +17,54 @@ type Model struct {
 // Endpoint represents how to reach a device.
 type Endpoint struct {
 	Address string // host:port or http(s)://ip ... depending on driver
+
+	// Credentials carries driver-specific auth material keyed by a
+	// driver-defined name (e.g. HiveOS's "rig_id"/"rig_passwd"/"api_token").
+	// Most drivers ignore it; it exists so endpoint-only configs don't have
+	// to grow a new top-level field per driver that needs authentication.
+	Credentials map[string]string
 }
 
 // Capability declares supported features of a driver/device.
 type Capability struct {
-	ReadStats           bool
-	ReadSummary         bool
-	ListPools           bool
-	ManagePools         bool // add/enable/disable/remove/switch
+	ReadStats   bool
+	ReadSummary bool
+	ListPools   bool
+	ManagePools bool // add/enable/disable/remove/switch
+
+	// AtomicPoolReplaceOnly reports that ManagePools is backed by a single
+	// "replace the whole pool list" write command rather than individual
+	// per-entry operations, so every AddPool/EnablePool/DisablePool/
+	// RemovePool call internally does a read-modify-write of the full list.
+	// Callers orchestrating pool changes across a fleet should avoid
+	// concurrent pool mutations against the same device when this is set,
+	// since a racing read-modify-write can silently drop the other change.
+	AtomicPoolReplaceOnly bool
+
 	Restart             bool
 	Quit                bool
 	Commands            []string // optional list of supported raw commands
 	FanControl          bool
 	PowerControl        bool
 	SupportedPowerModes []PowerModeKind
+
+	// MaxChains is the number of hashboards/chains the device exposes for
+	// per-chain tuning, or 0 if the driver doesn't know or doesn't support it.
+	MaxChains int
+	// SupportsPerChainTuning reports whether PowerMode.Chains is honored.
+	SupportsPerChainTuning bool
+	// TuneHashrate reports whether the driver supports higher-level
+	// autotune tuning (target hashrate, power limit, and an
+	// efficiency/thermal-envelope profile) beyond the flat PowerMode, via a
+	// driver-specific extension interface on its Session.
+	TuneHashrate bool
 }
 
 // Stats is a generic device metrics snapshot.
 type Stats struct {
 	Model      Model
-	Hashrate5s float64 // GH/s 5s window if available
-	HashrateAv float64 // GH/s average
+	Hashrate5s HashrateValue // 5s window if available
+	HashrateAv HashrateValue // average
 	TempMax    float64
 	UptimeSec  int64
 }
@@ -47,8 +74,8 @@ type Summary struct {
 	Accepted              int64
 	Rejected              int64
 	DeviceHardwarePercent float64
-	GHS5s                 float64
-	GHSav                 float64
+	GHS5s                 HashrateValue
+	GHSav                 HashrateValue
 }
 
 // Pool describes a configured pool on device.
@@ -75,8 +102,37 @@ type PowerMode struct {
 	Watts     int // optional target watts
 	VoltageMv int // optional millivolts
 	FreqMHz   int // optional MHz per chain
+
+	// Chains carries per-hashboard tuning for firmwares (Braiins OS, LuxOS,
+	// VNISH) that accept individual frequency/voltage per chain instead of a
+	// single flat Watts/VoltageMv/FreqMHz triple. Nil/empty means "use the
+	// flat fields for every chain", same as before this field existed.
+	Chains []ChainTuning
+	// TargetTHs requests the tuner aim for a specific hashrate instead of a
+	// fixed frequency/voltage; nil means unspecified.
+	TargetTHs *float64
+	// AutotuneMode selects the firmware's autotuner aggressiveness; empty
+	// means unspecified (driver default).
+	AutotuneMode AutotuneModeKind
 }
 
+// ChainTuning carries per-hashboard frequency/voltage tuning.
+type ChainTuning struct {
+	Index     int
+	FreqMHz   int
+	VoltageMv int
+	Enabled   bool
+}
+
+// AutotuneModeKind selects how aggressively a firmware's autotuner chases TargetTHs.
+type AutotuneModeKind string
+
+const (
+	AutotuneOff          AutotuneModeKind = "off"
+	AutotuneConservative AutotuneModeKind = "conservative"
+	AutotuneAggressive   AutotuneModeKind = "aggressive"
+)
+
 type FanModeKind string
 
 const (
@@ -117,6 +173,19 @@ type Session interface {
 	DisablePool(ctx context.Context, poolID int64) error
 	RemovePool(ctx context.Context, poolID int64) error
 	SwitchPool(ctx context.Context, poolID int64) error
+
+	// UpdatePool applies an optimistic-concurrency read-modify-write to the
+	// pool identified by poolID: it reads the pool's current state, passes
+	// it to tryUpdate, and writes back whatever tryUpdate returns. If the
+	// pool changed between the read and the write (another caller, or an
+	// edit made from the miner's own web UI) the driver retries from a
+	// fresh read a bounded number of times before giving up with
+	// ErrConflict, so a fleet-wide pool rewrite can't silently clobber an
+	// out-of-band change. Not every driver can rewrite every field in
+	// place; one that can't returns ErrNotImplemented for the fields it
+	// doesn't support instead of silently dropping them.
+	UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error
+
 	Restart(ctx context.Context) error
 	Quit(ctx context.Context) error
 
@@ -130,3 +199,12 @@ type Session interface {
 	GetFan(ctx context.Context) (FanConfig, error)
 	SetFan(ctx context.Context, fan FanConfig) error
 }
+
+// SessionHealthChecker is implemented by drivers whose sessions support a
+// liveness probe cheaper than a full Stats/Summary call (cgminer's "version"
+// RawCall, goldshell's GET /api/status). ConnectionPool.Run uses it to
+// validate idle sessions before handing them back out; a driver that
+// doesn't implement it gets a default probe via Session.Model.
+type SessionHealthChecker interface {
+	HealthCheck(ctx context.Context, sess Session) error
+}
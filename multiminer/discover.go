@@ -0,0 +1,167 @@
+package multiminer
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+)
+
+// DiscoveredMiner is a candidate device found by a Discoverer scan.
+type DiscoveredMiner struct {
+	Endpoint   Endpoint
+	DriverName string
+	Model      Model
+}
+
+// Classifier optionally refines or rejects a raw detection before it's
+// included in a scan's results, e.g. to filter by Model.Vendor or to attach
+// Credentials looked up from some external inventory. Returning ok=false
+// drops the candidate.
+type Classifier func(ctx context.Context, found DiscoveredMiner) (out DiscoveredMiner, ok bool)
+
+// DiscoverOptions controls how a Discoverer scans a range of addresses.
+type DiscoverOptions struct {
+	// Ports lists the candidate ports probed on every host. Required.
+	Ports []int
+	// Parallelism caps how many host:port candidates are probed concurrently.
+	// 0 means unbounded (one goroutine per candidate).
+	Parallelism int
+	// Classify, if set, is applied to every successful detection; see Classifier.
+	Classify Classifier
+}
+
+// Discoverer scans IP ranges for devices matching a Registry's drivers,
+// so an operator can bootstrap a farm inventory without a pre-existing list
+// of addresses.
+type Discoverer struct {
+	reg *Registry
+}
+
+// NewDiscoverer creates a Discoverer that probes drivers registered in reg.
+func NewDiscoverer(reg *Registry) *Discoverer {
+	return &Discoverer{reg: reg}
+}
+
+// ScanCIDR probes every host in cidr (e.g. "10.0.0.0/24") across opt.Ports.
+// Candidates are probed concurrently; for each one the registered drivers'
+// Detect runs in registration order, and the first driver that matches
+// yields the candidate's single DiscoveredMiner.
+func (d *Discoverer) ScanCIDR(ctx context.Context, cidr string, opt DiscoverOptions) ([]DiscoveredMiner, error) {
+	ips, err := hostsInCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return d.scanHosts(ctx, ips, opt), nil
+}
+
+// ScanHosts probes the given hosts (bare IPs or hostnames) across
+// opt.Ports, same as ScanCIDR but for an explicit list rather than a block.
+func (d *Discoverer) ScanHosts(ctx context.Context, hosts []string, opt DiscoverOptions) []DiscoveredMiner {
+	return d.scanHosts(ctx, hosts, opt)
+}
+
+func (d *Discoverer) scanHosts(ctx context.Context, hosts []string, opt DiscoverOptions) []DiscoveredMiner {
+	type candidate struct{ address string }
+
+	var candidates []candidate
+	for _, h := range hosts {
+		for _, p := range opt.Ports {
+			candidates = append(candidates, candidate{address: net.JoinHostPort(h, strconv.Itoa(p))})
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	parallelism := opt.Parallelism
+	if parallelism <= 0 || parallelism > len(candidates) {
+		parallelism = len(candidates)
+	}
+
+	var mu sync.Mutex
+	var found []DiscoveredMiner
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for _, c := range candidates {
+		c := c
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			dm, ok := d.probe(ctx, c.address)
+			if !ok {
+				return
+			}
+			if opt.Classify != nil {
+				dm, ok = opt.Classify(ctx, dm)
+				if !ok {
+					return
+				}
+			}
+
+			mu.Lock()
+			found = append(found, dm)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return found
+}
+
+// probe runs every registered driver's Detect against address, returning the
+// first match (if any) along with its reported Model.
+func (d *Discoverer) probe(ctx context.Context, address string) (DiscoveredMiner, bool) {
+	ep := Endpoint{Address: address}
+	drv, err := d.reg.Detect(ctx, ep)
+	if err != nil || drv == nil {
+		return DiscoveredMiner{}, false
+	}
+
+	dm := DiscoveredMiner{Endpoint: ep, DriverName: drv.Name()}
+
+	sess, err := drv.Open(ctx, ep)
+	if err != nil {
+		return dm, true
+	}
+	defer sess.Close()
+
+	if model, err := sess.Model(ctx); err == nil {
+		dm.Model = model
+	}
+	return dm, true
+}
+
+// hostsInCIDR expands a CIDR block into its individual host addresses,
+// dropping the first (network) and last (broadcast) addresses whenever the
+// block holds more than two, i.e. IPv4 blocks of /30 or wider. IPv6 blocks
+// are trimmed the same way.
+func hostsInCIDR(cidr string) ([]string, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, NewInvalidInputError("invalid CIDR: " + err.Error())
+	}
+
+	var hosts []string
+	for cur := ip.Mask(ipnet.Mask); ipnet.Contains(cur); incIP(cur) {
+		hosts = append(hosts, cur.String())
+	}
+
+	if len(hosts) > 2 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
+
+func incIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			return
+		}
+	}
+}
@@ -2,6 +2,7 @@ package multiminer
 
 import (
 	"context"
+	"encoding/json"
 	"net"
 	"strings"
 	"time"
@@ -16,7 +17,9 @@ func NewBraiinsDriver() Driver        { return &braiinsDriver{} }
 func (d *braiinsDriver) Name() string { return "braiins" }
 func (d *braiinsDriver) Capabilities() Capability {
 	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true, PowerControl: true, FanControl: true,
-		SupportedPowerModes: []PowerModeKind{PowerLow, PowerBalanced, PowerHigh}}
+		SupportedPowerModes:    []PowerModeKind{PowerLow, PowerBalanced, PowerHigh, PowerCustom},
+		MaxChains:              3,
+		SupportsPerChainTuning: true}
 }
 func (d *braiinsDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 	// Braiins OS typically runs cgminer-compatible API with Braiins-specific version info
@@ -76,8 +79,8 @@ func (s *braiinsSession) Stats(ctx context.Context) (Stats, error) {
 	g := st.Generic()
 	return Stats{
 		Model:      Model{Vendor: "Braiins", Product: g.Miner, Firmware: g.BMMiner},
-		Hashrate5s: g.Ghs5s.Float64(),
-		HashrateAv: g.GhsAverage,
+		Hashrate5s: HashrateFromGHS(g.Ghs5s.Float64()),
+		HashrateAv: HashrateFromGHS(g.GhsAverage),
 		TempMax:    float64(g.TempMax),
 		UptimeSec:  g.Elapsed,
 	}, nil
@@ -92,8 +95,8 @@ func (s *braiinsSession) Summary(ctx context.Context) (Summary, error) {
 		Accepted:              sm.Accepted,
 		Rejected:              sm.Rejected,
 		DeviceHardwarePercent: sm.DeviceHardwarePercent,
-		GHS5s:                 sm.GHS5s.Float64(),
-		GHSav:                 sm.GHSav,
+		GHS5s:                 HashrateFromGHS(sm.GHS5s.Float64()),
+		GHSav:                 HashrateFromGHS(sm.GHSav),
 	}, nil
 }
 
@@ -129,6 +132,10 @@ func (s *braiinsSession) SwitchPool(ctx context.Context, poolID int64) error {
 	return NewDeviceError("switch pool not implemented", "Braiins OS may not support pool switching", nil)
 }
 
+func (s *braiinsSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return NewDeviceError("update pool not implemented", "Braiins OS may not support in-place pool updates", ErrNotImplemented)
+}
+
 func (s *braiinsSession) Restart(ctx context.Context) error {
 	return s.c.CallContext(ctx, cg.NewCommandWithoutParameter("restart"), nil)
 }
@@ -148,8 +155,56 @@ func (s *braiinsSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
 }
 
 func (s *braiinsSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
-	// This is a stub - would use Braiins-specific power tuning APIs
-	return NewDeviceError("power mode setting not fully implemented", "would require Braiins-specific API calls", nil)
+	if mode.Kind != PowerCustom {
+		// Only the custom tuner profile is wired up today; other modes would
+		// require Braiins-specific API calls we haven't implemented yet.
+		return NewDeviceError("power mode setting not fully implemented", "would require Braiins-specific API calls", nil)
+	}
+	return s.setTunerConfig(ctx, mode)
+}
+
+// braiinsTunerConfig mirrors the shape of BraiinsOS's tuner_config.toml,
+// expressed as JSON for the cgminer Exec escape hatch.
+type braiinsTunerConfig struct {
+	TunerMode string               `json:"tuner_mode"`
+	TargetTHs *float64             `json:"target_th_s,omitempty"`
+	Chains    []braiinsChainConfig `json:"chains,omitempty"`
+}
+
+type braiinsChainConfig struct {
+	Index        int  `json:"index"`
+	FrequencyMHz int  `json:"frequency_mhz,omitempty"`
+	VoltageMv    int  `json:"voltage_mv,omitempty"`
+	Enabled      bool `json:"enabled"`
+}
+
+func (s *braiinsSession) setTunerConfig(ctx context.Context, mode PowerMode) error {
+	cfg := braiinsTunerConfig{TunerMode: "PowerTarget", TargetTHs: mode.TargetTHs}
+	switch mode.AutotuneMode {
+	case AutotuneOff:
+		cfg.TunerMode = "Disabled"
+	case AutotuneAggressive:
+		cfg.TunerMode = "HashrateTarget"
+	}
+
+	for _, c := range mode.Chains {
+		cfg.Chains = append(cfg.Chains, braiinsChainConfig{
+			Index:        c.Index,
+			FrequencyMHz: c.FreqMHz,
+			VoltageMv:    c.VoltageMv,
+			Enabled:      c.Enabled,
+		})
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return NewInvalidInputError("failed to encode tuner config")
+	}
+
+	if _, err := s.Exec(ctx, "tuner_config", string(data)); err != nil {
+		return NewDeviceError("failed to apply tuner config", "braiins tuner_config rejected the request", err)
+	}
+	return nil
 }
 
 func (s *braiinsSession) GetFan(ctx context.Context) (FanConfig, error) {
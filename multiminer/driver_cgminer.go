@@ -11,9 +11,20 @@ import (
 )
 
 // cgminerDriver adapts cgminer/BMminer JSON API devices.
-type cgminerDriver struct{}
+type cgminerDriver struct {
+	logger Logger
+}
+
+func NewCGMinerDriver() Driver { return &cgminerDriver{logger: &NoOpLogger{}} }
 
-func NewCGMinerDriver() Driver { return &cgminerDriver{} }
+// NewCGMinerDriverWithLogger creates a cgminer driver whose sessions log
+// open/close and command outcomes through logger.
+func NewCGMinerDriverWithLogger(logger Logger) Driver {
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	return &cgminerDriver{logger: logger}
+}
 
 func (d *cgminerDriver) Name() string { return "cgminer" }
 
@@ -34,6 +45,18 @@ func (d *cgminerDriver) Capabilities() Capability {
 	}
 }
 
+// HealthCheck implements SessionHealthChecker with the same lightweight
+// Version call Detect uses, so an idle pooled session is validated without
+// paying for a full Stats/Summary round trip.
+func (d *cgminerDriver) HealthCheck(ctx context.Context, sess Session) error {
+	cs, ok := sess.(*cgSession)
+	if !ok {
+		return NewDeviceError("health check unsupported", "session is not a cgminer session", nil)
+	}
+	_, err := cs.c.VersionContext(ctx)
+	return err
+}
+
 // Detect tries a lightweight Version call with a short timeout.
 func (d *cgminerDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 	// Attempt to open and call Version; keep short timeout to avoid blocking.
@@ -57,7 +80,10 @@ func (d *cgminerDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 }
 
 // cgSession implements Session backed by cgminer client.
-type cgSession struct{ c *cg.CGMiner }
+type cgSession struct {
+	c      *cg.CGMiner
+	logger Logger
+}
 
 func (d *cgminerDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
 	client := &cg.CGMiner{
@@ -66,7 +92,12 @@ func (d *cgminerDriver) Open(ctx context.Context, ep Endpoint) (Session, error)
 		Transport: cg.NewJSONTransport(),
 		Dialer:    &net.Dialer{Timeout: 3 * time.Second},
 	}
-	return &cgSession{c: client}, nil
+	logger := d.logger
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	logger.Debug(ctx, "opened cgminer session", F("endpoint", ep.Address))
+	return &cgSession{c: client, logger: logger.WithFields(F("endpoint", ep.Address))}, nil
 }
 
 func (s *cgSession) Close() error { return nil }
@@ -82,13 +113,14 @@ func (s *cgSession) Model(ctx context.Context) (Model, error) {
 func (s *cgSession) Stats(ctx context.Context) (Stats, error) {
 	st, err := s.c.StatsContext(ctx)
 	if err != nil {
+		s.logger.Warn(ctx, "stats call failed", F("error", err))
 		return Stats{}, err
 	}
 	g := st.Generic()
 	return Stats{
 		Model:      Model{Vendor: g.Type, Product: g.Miner, Firmware: g.BMMiner},
-		Hashrate5s: g.Ghs5s.Float64(),
-		HashrateAv: g.GhsAverage,
+		Hashrate5s: HashrateFromGHS(g.Ghs5s.Float64()),
+		HashrateAv: HashrateFromGHS(g.GhsAverage),
 		TempMax:    float64(g.TempMax),
 		UptimeSec:  g.Elapsed,
 	}, nil
@@ -103,8 +135,8 @@ func (s *cgSession) Summary(ctx context.Context) (Summary, error) {
 		Accepted:              sm.Accepted,
 		Rejected:              sm.Rejected,
 		DeviceHardwarePercent: sm.DeviceHardwarePercent,
-		GHS5s:                 sm.GHS5s.Float64(),
-		GHSav:                 sm.GHSav,
+		GHS5s:                 HashrateFromGHS(sm.GHS5s.Float64()),
+		GHSav:                 HashrateFromGHS(sm.GHSav),
 	}, nil
 }
 
@@ -135,6 +167,76 @@ func (s *cgSession) RemovePool(ctx context.Context, poolID int64) error {
 func (s *cgSession) SwitchPool(ctx context.Context, poolID int64) error {
 	return s.c.CallContext(ctx, cg.NewCommand("switchpool", fmt.Sprint(poolID)), nil)
 }
+
+// maxUpdatePoolRetries bounds UpdatePool's optimistic-concurrency loop:
+// after this many conflicting re-reads it gives up and returns ErrConflict.
+const maxUpdatePoolRetries = 5
+
+// UpdatePool mirrors etcd3's GuaranteedUpdate: read poolID's current state,
+// run tryUpdate against it, write the result, then re-read to check nothing
+// raced the write. A mismatch (another caller, or a change made from the
+// miner's own web UI) retries from a fresh read, for at most
+// maxUpdatePoolRetries attempts in all, before giving up with ErrConflict.
+//
+// cgminer's wire protocol only supports enabling/disabling a pool by ID
+// (enablepool/disablepool/switchpool); it has no command to rewrite a
+// pool's URL, credentials, or priority in place. tryUpdate may only change
+// Pool.Active - any other field change it returns is rejected.
+func (s *cgSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	for attempt := 0; attempt < maxUpdatePoolRetries; attempt++ {
+		current, err := s.findPool(ctx, poolID)
+		if err != nil {
+			return err
+		}
+
+		desired, err := tryUpdate(*current)
+		if err != nil {
+			return err
+		}
+		if desired.URL != current.URL || desired.User != current.User || desired.Priority != current.Priority {
+			return NewDeviceError("update pool not supported", "cgminer can only toggle Active; URL/User/Priority are immutable via its protocol", ErrNotImplemented)
+		}
+		if desired.Active == current.Active {
+			return nil
+		}
+
+		if desired.Active {
+			err = s.SwitchPool(ctx, poolID)
+		} else {
+			err = s.DisablePool(ctx, poolID)
+		}
+		if err != nil {
+			return err
+		}
+
+		after, err := s.findPool(ctx, poolID)
+		if err != nil {
+			return err
+		}
+		if after.Active == desired.Active {
+			return nil
+		}
+		// Someone else changed the pool's active state between our write
+		// and this re-read; retry from a fresh read.
+	}
+	return ErrConflict
+}
+
+// findPool returns the current state of poolID, or NewNotFoundError if no
+// pool with that ID is configured.
+func (s *cgSession) findPool(ctx context.Context, poolID int64) (*Pool, error) {
+	pools, err := s.Pools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range pools {
+		if pools[i].ID == poolID {
+			return &pools[i], nil
+		}
+	}
+	return nil, NewNotFoundError(fmt.Sprintf("pool %d not found", poolID))
+}
+
 func (s *cgSession) Restart(ctx context.Context) error {
 	return s.c.CallContext(ctx, cg.NewCommandWithoutParameter("restart"), nil)
 }
@@ -7,12 +7,47 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/x1unix/go-cgminer-api/multiminer/httpx"
 )
 
+// GoldshellOptions configures a Goldshell driver instance.
+type GoldshellOptions struct {
+	// HTTP configures the shared transport (keep-alive, mTLS, retry with
+	// jittered backoff) every session opened by this driver uses instead
+	// of building a fresh *http.Client per call. The zero value is a plain
+	// HTTP client with no retries.
+	HTTP httpx.Config
+}
+
 // Driver stub for Goldshell.
-type goldshellDriver struct{}
+type goldshellDriver struct {
+	logger Logger
+	opt    GoldshellOptions
+}
+
+func NewGoldshellDriver() Driver { return &goldshellDriver{logger: &NoOpLogger{}} }
+
+// NewGoldshellDriverWithLogger creates a Goldshell driver whose sessions log
+// HTTP call outcomes through logger.
+func NewGoldshellDriverWithLogger(logger Logger) Driver {
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	return &goldshellDriver{logger: logger}
+}
+
+// NewGoldshellDriverWithOptions creates a Goldshell driver whose sessions
+// share opt.HTTP's transport and retry policy, for reaching firmware over
+// HTTPS/mTLS or behind a reverse proxy, or tuning idle-connection/retry
+// behavior for a large fleet.
+func NewGoldshellDriverWithOptions(logger Logger, opt GoldshellOptions) Driver {
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	return &goldshellDriver{logger: logger, opt: opt}
+}
 
-func NewGoldshellDriver() Driver { return &goldshellDriver{} }
 func (d *goldshellDriver) Name() string { return "goldshell" }
 func (d *goldshellDriver) Capabilities() Capability {
 	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
@@ -21,12 +56,12 @@ func (d *goldshellDriver) Detect(ctx context.Context, ep Endpoint) (bool, error)
 	// Goldshell miners typically expose HTTP API on port 80 or 8080
 	// Try to detect via HTTP API endpoints
 	candidates := []string{"/mcb/status", "/api/status", "/status", "/"}
-	
+
 	path, found := probeHTTP(ctx, ep.Address, candidates, 1200*time.Millisecond)
 	if !found {
 		return false, nil
 	}
-	
+
 	// Try to get more info from the status endpoint
 	client := &http.Client{Timeout: 1200 * time.Millisecond}
 	url := fmt.Sprintf("http://%s%s", ep.Address, path)
@@ -36,133 +71,244 @@ func (d *goldshellDriver) Detect(ctx context.Context, ep Endpoint) (bool, error)
 		return true, nil // We found HTTP response, assume it's Goldshell
 	}
 	defer resp.Body.Close()
-	
+
 	// Look for Goldshell-specific indicators in response
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return true, nil // HTTP response found, assume Goldshell
 	}
-	
+
 	// Check for Goldshell-specific keys in JSON response
 	respStr := strings.ToLower(fmt.Sprintf("%v", result))
-	if strings.Contains(respStr, "goldshell") || 
-	   strings.Contains(respStr, "kd-box") ||
-	   strings.Contains(respStr, "hs-box") {
+	if strings.Contains(respStr, "goldshell") ||
+		strings.Contains(respStr, "kd-box") ||
+		strings.Contains(respStr, "hs-box") {
 		return true, nil
 	}
-	
+
 	return true, nil // If we got a proper JSON response, assume it's Goldshell
 }
 
+// HealthCheck implements SessionHealthChecker by pinging the same
+// /api/status endpoint Detect probes, without parsing its body.
+func (d *goldshellDriver) HealthCheck(ctx context.Context, sess Session) error {
+	gs, ok := sess.(*goldshellSession)
+	if !ok {
+		return NewDeviceError("health check unsupported", "session is not a goldshell session", nil)
+	}
+
+	client, err := gs.ensureClient()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodGet, client.URL("/api/status"), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(ctx, req)
+	if err != nil {
+		return NewConnectionError("health check failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return NewDeviceError("health check failed", resp.Status, nil)
+	}
+	return nil
+}
+
 func (d *goldshellDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
-	return &goldshellSession{address: ep.Address}, nil
+	logger := d.logger
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	return &goldshellSession{
+		address: ep.Address,
+		creds:   ep.Credentials,
+		opt:     d.opt,
+		logger:  logger.WithFields(F("endpoint", ep.Address)),
+	}, nil
 }
 
 // goldshellSession implements Session for Goldshell devices
 type goldshellSession struct {
 	address string
+	creds   map[string]string
+	opt     GoldshellOptions
+	logger  Logger
+
+	client *httpx.Client
+}
+
+// ensureClient lazily builds s.client, sharing it (and its keep-alive
+// *http.Client) across every call this session makes. If s.opt.HTTP didn't
+// set a Login and s.creds carries Goldshell's "username"/"password" keys,
+// a default login against /user/login is wired in so the client
+// transparently re-authenticates on a 401 instead of every call failing
+// once the firmware's auth token rotates out from under it.
+func (s *goldshellSession) ensureClient() (*httpx.Client, error) {
+	if s.client != nil {
+		return s.client, nil
+	}
+
+	cfg := s.opt.HTTP
+	if cfg.Login == nil {
+		if user, pass := s.creds["username"], s.creds["password"]; user != "" || pass != "" {
+			cfg.Login = goldshellLogin(user, pass)
+		}
+	}
+
+	client, err := httpx.New(s.address, cfg)
+	if err != nil {
+		return nil, NewConnectionError("failed to build http client", err)
+	}
+	s.client = client
+	return s.client, nil
+}
+
+// goldshellLogin authenticates against Goldshell's /user/login endpoint,
+// which returns a token the firmware expects back as the "auth" header on
+// every subsequent request. It sends its login request via Client.Raw,
+// never Client.Do, so it doesn't recurse back into its own auth flow.
+func goldshellLogin(user, pass string) func(ctx context.Context, c *httpx.Client) error {
+	return func(ctx context.Context, c *httpx.Client) error {
+		body := strings.NewReader(fmt.Sprintf(`{"username":%q,"password":%q}`, user, pass))
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL("/user/login"), body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.Raw(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return NewDeviceError("goldshell login failed", resp.Status, nil)
+		}
+
+		var result struct {
+			Token string `json:"token"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return fmt.Errorf("failed to parse login response: %w", err)
+		}
+		if result.Token == "" {
+			return fmt.Errorf("login response carried no token")
+		}
+		c.SetAuthHeader("auth", result.Token)
+		return nil
+	}
 }
 
 func (s *goldshellSession) Close() error { return nil }
 
 func (s *goldshellSession) Model(ctx context.Context) (Model, error) {
-	// Try to get device info from HTTP API
-	client := &http.Client{Timeout: 3 * time.Second}
-	url := fmt.Sprintf("http://%s/api/status", s.address)
-	
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := client.Do(req)
+	client, err := s.ensureClient()
+	if err != nil {
+		return Model{Vendor: "Goldshell", Product: "Unknown", Firmware: "Unknown"}, nil
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, client.URL("/api/status"), nil)
+	resp, err := client.Do(ctx, req)
 	if err != nil {
 		return Model{Vendor: "Goldshell", Product: "Unknown", Firmware: "Unknown"}, nil
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return Model{Vendor: "Goldshell", Product: "Unknown", Firmware: "Unknown"}, nil
 	}
-	
+
 	product := "Unknown"
 	firmware := "Unknown"
-	
+
 	if model, ok := result["model"].(string); ok {
 		product = model
 	}
 	if fw, ok := result["firmware"].(string); ok {
 		firmware = fw
 	}
-	
+
 	return Model{Vendor: "Goldshell", Product: product, Firmware: firmware}, nil
 }
 
 func (s *goldshellSession) Stats(ctx context.Context) (Stats, error) {
 	model, _ := s.Model(ctx)
-	
-	client := &http.Client{Timeout: 3 * time.Second}
-	url := fmt.Sprintf("http://%s/api/stats", s.address)
-	
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := client.Do(req)
+
+	client, err := s.ensureClient()
+	if err != nil {
+		return Stats{Model: model}, err
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, client.URL("/api/stats"), nil)
+	resp, err := client.Do(ctx, req)
 	if err != nil {
+		s.logger.Warn(ctx, "stats call failed", F("error", err))
 		return Stats{Model: model}, NewConnectionError("failed to get stats", err)
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return Stats{Model: model}, NewDeviceError("failed to parse stats", "invalid JSON response", err)
 	}
-	
+
 	stats := Stats{Model: model}
-	
+
 	if hashrate, ok := result["hashrate"].(float64); ok {
-		stats.HashrateAv = hashrate / 1000000000 // Convert to GH/s
-		stats.Hashrate5s = stats.HashrateAv     // Use same value for 5s
+		stats.HashrateAv = HashrateValue(hashrate) // result is already in H/s
+		stats.Hashrate5s = stats.HashrateAv        // Use same value for 5s
 	}
-	
+
 	if temp, ok := result["temperature"].(float64); ok {
 		stats.TempMax = temp
 	}
-	
+
 	if uptime, ok := result["uptime"].(float64); ok {
 		stats.UptimeSec = int64(uptime)
 	}
-	
+
 	return stats, nil
 }
 
 func (s *goldshellSession) Summary(ctx context.Context) (Summary, error) {
-	client := &http.Client{Timeout: 3 * time.Second}
-	url := fmt.Sprintf("http://%s/api/summary", s.address)
-	
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := client.Do(req)
+	client, err := s.ensureClient()
+	if err != nil {
+		return Summary{}, err
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, client.URL("/api/summary"), nil)
+	resp, err := client.Do(ctx, req)
 	if err != nil {
 		return Summary{}, NewConnectionError("failed to get summary", err)
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return Summary{}, NewDeviceError("failed to parse summary", "invalid JSON response", err)
 	}
-	
+
 	summary := Summary{}
-	
+
 	if accepted, ok := result["accepted"].(float64); ok {
 		summary.Accepted = int64(accepted)
 	}
-	
+
 	if rejected, ok := result["rejected"].(float64); ok {
 		summary.Rejected = int64(rejected)
 	}
-	
+
 	if hashrate, ok := result["hashrate"].(float64); ok {
-		ghash := hashrate / 1000000000 // Convert to GH/s
-		summary.GHSav = ghash
-		summary.GHS5s = ghash
+		hr := HashrateValue(hashrate) // result is already in H/s
+		summary.GHSav = hr
+		summary.GHS5s = hr
 	}
-	
+
 	return summary, nil
 }
 
@@ -190,6 +336,10 @@ func (s *goldshellSession) SwitchPool(ctx context.Context, poolID int64) error {
 	return NewDeviceError("switch pool not implemented", "Goldshell pool management not implemented", nil)
 }
 
+func (s *goldshellSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return NewDeviceError("update pool not implemented", "Goldshell pool management via HTTP API not implemented", ErrNotImplemented)
+}
+
 func (s *goldshellSession) Restart(ctx context.Context) error {
 	return NewDeviceError("restart not implemented", "Goldshell restart via HTTP API not implemented", nil)
 }
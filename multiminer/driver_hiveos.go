@@ -1,15 +1,153 @@
 package multiminer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 )
 
-// Driver stub for HiveOS (local agent)
+// defaultRigConfPath is where the hive-client agent keeps its on-box
+// configuration, read when multiminer itself happens to run on the rig.
+const defaultRigConfPath = "/hive-config/rig.conf"
+
+// hiveAPIBase is the Hive API v2 base URL used for commands the local agent
+// doesn't accept directly (pool edits, power/fan tuning): these take effect
+// once Hive's backend pushes the updated flight sheet/config back down.
+const hiveAPIBase = "https://api2.hiveos.farm/api/v2"
+
+// hiveRigConfig is the subset of rig.conf / Endpoint.Credentials the driver
+// needs: RIG_ID/RIG_PASSWD sign requests to the local agent, while
+// FarmID/WorkerID/APIToken address and authenticate Hive API v2 calls.
+type hiveRigConfig struct {
+	RigID     string
+	RigPasswd string
+	FarmID    string
+	WorkerID  string
+	APIToken  string
+}
+
+func (c hiveRigConfig) hasLocalAuth() bool { return c.RigID != "" && c.RigPasswd != "" }
+func (c hiveRigConfig) hasAPIAccess() bool {
+	return c.APIToken != "" && c.FarmID != "" && c.WorkerID != ""
+}
+
+// readRigConf parses a hive-client rig.conf file: a flat shell-style
+// KEY=VALUE list, values optionally quoted.
+func readRigConf(path string) (hiveRigConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return hiveRigConfig{}, err
+	}
+	defer f.Close()
+
+	var cfg hiveRigConfig
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		val := strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+		switch strings.TrimSpace(parts[0]) {
+		case "RIG_ID":
+			cfg.RigID = val
+		case "RIG_PASSWD":
+			cfg.RigPasswd = val
+		case "FARM_HASH":
+			cfg.FarmID = val
+		case "WORKER_NAME":
+			cfg.WorkerID = val
+		}
+	}
+	return cfg, scanner.Err()
+}
+
+// credentialConfig builds a hiveRigConfig from Endpoint.Credentials, the way
+// a multiminer deployment that isn't running on-box supplies them.
+func credentialConfig(ep Endpoint) hiveRigConfig {
+	return hiveRigConfig{
+		RigID:     ep.Credentials["rig_id"],
+		RigPasswd: ep.Credentials["rig_passwd"],
+		FarmID:    ep.Credentials["farm_id"],
+		WorkerID:  ep.Credentials["worker_id"],
+		APIToken:  ep.Credentials["api_token"],
+	}
+}
+
+// resolveRigConfig prefers an on-box rig.conf, layering in the API token
+// (which rig.conf never carries) and any farm/worker IDs from
+// Endpoint.Credentials; non-empty credential values override rig.conf's
+// FARM_HASH/WORKER_NAME. It falls back entirely to Endpoint.Credentials when
+// there's no rig.conf or it lacks RIG_ID/RIG_PASSWD.
+func resolveRigConfig(ep Endpoint) hiveRigConfig {
+	cfg, err := readRigConf(defaultRigConfPath)
+	if err != nil || !cfg.hasLocalAuth() {
+		return credentialConfig(ep)
+	}
+
+	cred := credentialConfig(ep)
+	if cred.FarmID != "" {
+		cfg.FarmID = cred.FarmID
+	}
+	if cred.WorkerID != "" {
+		cfg.WorkerID = cred.WorkerID
+	}
+	if cred.APIToken != "" {
+		cfg.APIToken = cred.APIToken
+	}
+	return cfg
+}
+
+// helloRequest performs the hive-client agent's hello handshake, signed
+// with RIG_ID/RIG_PASSWD. It returns nil on HTTP 200 and does not read the
+// response body.
+func helloRequest(ctx context.Context, client *http.Client, address string, cfg hiveRigConfig) error {
+	mac := hmac.New(sha256.New, []byte(cfg.RigPasswd))
+	mac.Write([]byte(cfg.RigID))
+	sig := hex.EncodeToString(mac.Sum(nil))
+
+	body, _ := json.Marshal(map[string]string{"rig_id": cfg.RigID, "sign": sig})
+	url := fmt.Sprintf("http://%s/hello", address)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("hello: unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// hiveOSDriver talks to the hive-client local agent for telemetry
+// (miner_stats/flight sheet via /worker-stats) and, when an API token is
+// available, the Hive API v2 for the pool and tuning commands the local
+// agent doesn't accept directly.
+//
+// Capabilities() reports the driver's maximal feature set, same as every
+// other driver here; an individual session without API access reports that
+// at the Session level instead, returning a NewPermanentError from the
+// gated methods rather than silently failing, since the Driver interface
+// has no per-endpoint hook to narrow it up front.
 type hiveOSDriver struct{}
 
 func NewHiveOSDriver() Driver        { return &hiveOSDriver{} }
@@ -17,65 +155,83 @@ func (d *hiveOSDriver) Name() string { return "hiveos" }
 func (d *hiveOSDriver) Capabilities() Capability {
 	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
 }
-func (d *hiveOSDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
-	// HiveOS typically exposes local APIs on common ports
-	// Try to detect HiveOS-specific endpoints
-	candidates := []string{
-		"/hive/v1/stats",
-		"/api/v1/stats",
-		"/hiveos/stats",
-		"/agent/stats",
-	}
 
-	if _, found := probeHTTP(ctx, ep.Address, candidates, 1200*time.Millisecond); found {
-		return true, nil
+// Detect signs a hello request to the local agent with RIG_ID/RIG_PASSWD,
+// resolved from an on-box rig.conf or Endpoint.Credentials.
+func (d *hiveOSDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
+	cfg := resolveRigConfig(ep)
+	if !cfg.hasLocalAuth() {
+		return false, nil
 	}
 
-	// Also try to detect by looking for HiveOS-specific response patterns
 	client := &http.Client{Timeout: 1200 * time.Millisecond}
-
-	// Try common status endpoints
-	statusUrls := []string{
-		fmt.Sprintf("http://%s/", ep.Address),
-		fmt.Sprintf("http://%s/api/status", ep.Address),
+	if err := helloRequest(ctx, client, ep.Address, cfg); err != nil {
+		return false, nil
 	}
+	return true, nil
+}
 
-	for _, url := range statusUrls {
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := client.Do(req)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
+func (d *hiveOSDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
+	return &hiveOSSession{address: ep.Address, cfg: resolveRigConfig(ep)}, nil
+}
 
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
+// hiveWorkerStats is the hive-client agent's /worker-stats payload.
+type hiveWorkerStats struct {
+	Miner       string          `json:"miner"`
+	MinerStats  hiveMinerStats  `json:"miner_stats"`
+	FlightSheet hiveFlightSheet `json:"flight_sheet"`
+}
 
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			continue
-		}
+// hiveMinerStats holds per-algo hashrates (Hs, scaled by HsUnits) and
+// per-board temp/fan readings, and [accepted, rejected] in Ar.
+type hiveMinerStats struct {
+	Hs      []float64 `json:"hs"`
+	HsUnits string    `json:"hs_units"`
+	Uptime  int64     `json:"uptime"`
+	Temp    []float64 `json:"temp"`
+	Fan     []float64 `json:"fan"`
+	Ar      []int64   `json:"ar"`
+}
 
-		// Look for HiveOS-specific identifiers
-		respStr := strings.ToLower(fmt.Sprintf("%v", result))
-		if strings.Contains(respStr, "hiveos") ||
-			strings.Contains(respStr, "hive") ||
-			strings.Contains(respStr, "agent") {
-			return true, nil
-		}
-	}
+// hiveFlightSheet is the parsed pool configuration currently pushed to the rig.
+type hiveFlightSheet struct {
+	Pools []hivePoolEntry `json:"pools"`
+}
 
-	return false, nil
+type hivePoolEntry struct {
+	URL      string `json:"url"`
+	User     string `json:"user"`
+	Pass     string `json:"pass"`
+	Priority int64  `json:"priority"`
+	Active   bool   `json:"active"`
 }
 
-func (d *hiveOSDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
-	return &hiveOSSession{address: ep.Address}, nil
+// hashUnitToGHsFactor converts a hive-client hs_units string (its reporting
+// unit defaults to Mh/s) to a GH/s multiplier.
+func hashUnitToGHsFactor(unit string) float64 {
+	switch strings.ToLower(unit) {
+	case "h", "h/s":
+		return 1e-9
+	case "kh", "kh/s":
+		return 1e-6
+	case "mh", "mh/s":
+		return 1e-3
+	case "gh", "gh/s":
+		return 1
+	case "th", "th/s":
+		return 1e3
+	case "ph", "ph/s":
+		return 1e6
+	default:
+		return 1e-3
+	}
 }
 
-// hiveOSSession implements Session for HiveOS devices
+// hiveOSSession implements Session against the hive-client local agent and,
+// where cfg has API access, the Hive API v2.
 type hiveOSSession struct {
 	address    string
+	cfg        hiveRigConfig
 	httpClient *http.Client
 }
 
@@ -87,330 +243,230 @@ func (s *hiveOSSession) ensureClient() {
 
 func (s *hiveOSSession) Close() error { return nil }
 
-func (s *hiveOSSession) Model(ctx context.Context) (Model, error) {
+// fetchWorkerStats pulls the agent's combined telemetry + flight sheet
+// snapshot, replacing the old driver's brute-force candidate-URL probing
+// with the one endpoint the agent actually serves.
+func (s *hiveOSSession) fetchWorkerStats(ctx context.Context) (hiveWorkerStats, error) {
 	s.ensureClient()
-
-	// Try to get device info from various HiveOS endpoints
-	endpoints := []string{
-		"/hive/v1/info",
-		"/api/v1/info",
-		"/hiveos/info",
-		"/agent/info",
-		"/api/status",
+	url := fmt.Sprintf("http://%s/worker-stats", s.address)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return hiveWorkerStats{}, NewTransientError("failed to build stats request", err.Error(), err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return hiveWorkerStats{}, NewTransientError("hive-client agent unreachable", err.Error(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return hiveWorkerStats{}, NewTransientError("hive-client agent error", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
 	}
 
-	for _, endpoint := range endpoints {
-		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
-
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			continue
-		}
-
-		model := Model{Vendor: "HiveOS", Product: "Unknown", Firmware: "HiveOS"}
-
-		// Extract model information
-		if hw, ok := result["hardware"].(string); ok {
-			model.Product = hw
-		} else if minerType, ok := result["miner_type"].(string); ok {
-			model.Product = minerType
-		} else if board, ok := result["board"].(string); ok {
-			model.Product = board
-		}
-
-		if fw, ok := result["firmware"].(string); ok {
-			model.Firmware = fw
-		} else if version, ok := result["version"].(string); ok {
-			model.Firmware = "HiveOS " + version
-		} else if hiveVersion, ok := result["hive_version"].(string); ok {
-			model.Firmware = "HiveOS " + hiveVersion
-		}
-
-		return model, nil
+	var ws hiveWorkerStats
+	if err := json.NewDecoder(resp.Body).Decode(&ws); err != nil {
+		return hiveWorkerStats{}, NewTransientError("invalid worker-stats payload", err.Error(), err)
 	}
+	return ws, nil
+}
 
-	return Model{Vendor: "HiveOS", Product: "Unknown", Firmware: "HiveOS"}, nil
+func (s *hiveOSSession) Model(ctx context.Context) (Model, error) {
+	ws, err := s.fetchWorkerStats(ctx)
+	if err != nil {
+		return Model{Vendor: "HiveOS", Firmware: "hive-client"}, err
+	}
+	return Model{Vendor: "HiveOS", Product: ws.Miner, Firmware: "hive-client"}, nil
 }
 
 func (s *hiveOSSession) Stats(ctx context.Context) (Stats, error) {
-	s.ensureClient()
-	model, _ := s.Model(ctx)
-
-	// Try to get stats from HiveOS endpoints
-	endpoints := []string{
-		"/hive/v1/stats",
-		"/api/v1/stats",
-		"/hiveos/stats",
-		"/agent/stats",
-		"/api/stats",
-	}
-
-	for _, endpoint := range endpoints {
-		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
-
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			continue
-		}
-
-		stats := Stats{Model: model}
-
-		// Extract hashrate (HiveOS may report in various formats)
-		if miners, ok := result["miners"].([]interface{}); ok && len(miners) > 0 {
-			// Aggregate hashrate from all miners
-			totalHashrate := 0.0
-			for _, miner := range miners {
-				if minerMap, ok := miner.(map[string]interface{}); ok {
-					if hr, ok := minerMap["hashrate"].(float64); ok {
-						totalHashrate += hr
-					} else if hrStr, ok := minerMap["hashrate"].(string); ok {
-						// Parse hashrate string if needed
-						var hr float64
-						fmt.Sscanf(hrStr, "%f", &hr)
-						totalHashrate += hr
-					}
-				}
-			}
-			stats.Hashrate5s = totalHashrate / 1000000000 // Convert to GH/s
-			stats.HashrateAv = stats.Hashrate5s
-		} else if hashrate, ok := result["hashrate"].(float64); ok {
-			stats.Hashrate5s = hashrate / 1000000000 // Convert to GH/s
-			stats.HashrateAv = stats.Hashrate5s
-		}
+	ws, err := s.fetchWorkerStats(ctx)
+	if err != nil {
+		return Stats{Model: Model{Vendor: "HiveOS", Firmware: "hive-client"}}, err
+	}
 
-		// Extract temperature
-		if temp, ok := result["temp_max"].(float64); ok {
-			stats.TempMax = temp
-		} else if temp, ok := result["temperature"].(float64); ok {
-			stats.TempMax = temp
-		} else if temps, ok := result["temps"].([]interface{}); ok && len(temps) > 0 {
-			// Find max temperature
-			maxTemp := 0.0
-			for _, t := range temps {
-				if temp, ok := t.(float64); ok && temp > maxTemp {
-					maxTemp = temp
-				}
-			}
-			stats.TempMax = maxTemp
-		}
+	factor := hashUnitToGHsFactor(ws.MinerStats.HsUnits)
+	var total float64
+	for _, hs := range ws.MinerStats.Hs {
+		total += hs
+	}
+	ghs := total * factor
 
-		// Extract uptime
-		if uptime, ok := result["uptime"].(float64); ok {
-			stats.UptimeSec = int64(uptime)
+	stats := Stats{
+		Model:      Model{Vendor: "HiveOS", Product: ws.Miner, Firmware: "hive-client"},
+		Hashrate5s: HashrateFromGHS(ghs),
+		HashrateAv: HashrateFromGHS(ghs),
+		UptimeSec:  ws.MinerStats.Uptime,
+	}
+	for _, t := range ws.MinerStats.Temp {
+		if t > stats.TempMax {
+			stats.TempMax = t
 		}
-
-		return stats, nil
 	}
-
-	return Stats{Model: model}, NewDeviceError("stats not available", "no working HiveOS stats endpoint found", nil)
+	return stats, nil
 }
 
 func (s *hiveOSSession) Summary(ctx context.Context) (Summary, error) {
-	s.ensureClient()
-
-	// Use stats data to build summary
-	stats, err := s.Stats(ctx)
+	ws, err := s.fetchWorkerStats(ctx)
 	if err != nil {
 		return Summary{}, err
 	}
 
-	summary := Summary{
-		GHS5s: stats.Hashrate5s,
-		GHSav: stats.HashrateAv,
+	factor := hashUnitToGHsFactor(ws.MinerStats.HsUnits)
+	var total float64
+	for _, hs := range ws.MinerStats.Hs {
+		total += hs
 	}
+	ghs := total * factor
 
-	// Try to get pool stats if available
-	endpoints := []string{
-		"/hive/v1/pools",
-		"/api/v1/pools",
-		"/hiveos/pools",
-		"/api/pools",
+	summary := Summary{GHS5s: HashrateFromGHS(ghs), GHSav: HashrateFromGHS(ghs)}
+	if len(ws.MinerStats.Ar) >= 2 {
+		summary.Accepted = ws.MinerStats.Ar[0]
+		summary.Rejected = ws.MinerStats.Ar[1]
 	}
-
-	for _, endpoint := range endpoints {
-		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
-
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			continue
-		}
-
-		// Extract accepted/rejected shares
-		if accepted, ok := result["accepted"].(float64); ok {
-			summary.Accepted = int64(accepted)
-		}
-
-		if rejected, ok := result["rejected"].(float64); ok {
-			summary.Rejected = int64(rejected)
-		}
-
-		break
-	}
-
 	return summary, nil
 }
 
 func (s *hiveOSSession) Pools(ctx context.Context) ([]Pool, error) {
-	s.ensureClient()
-
-	endpoints := []string{
-		"/hive/v1/pools",
-		"/api/v1/pools",
-		"/hiveos/pools",
-		"/api/pools",
+	ws, err := s.fetchWorkerStats(ctx)
+	if err != nil {
+		return nil, err
 	}
+	out := make([]Pool, 0, len(ws.FlightSheet.Pools))
+	for i, p := range ws.FlightSheet.Pools {
+		out = append(out, Pool{ID: int64(i), URL: p.URL, User: p.User, Priority: p.Priority, Active: p.Active})
+	}
+	return out, nil
+}
 
-	for _, endpoint := range endpoints {
-		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			continue
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			continue
-		}
-
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-			continue
-		}
-
-		var pools []Pool
-
-		if poolsList, ok := result["pools"].([]interface{}); ok {
-			for i, p := range poolsList {
-				if poolMap, ok := p.(map[string]interface{}); ok {
-					pool := Pool{ID: int64(i)}
-
-					if url, ok := poolMap["url"].(string); ok {
-						pool.URL = url
-					}
-
-					if user, ok := poolMap["user"].(string); ok {
-						pool.User = user
-					}
-
-					if priority, ok := poolMap["priority"].(float64); ok {
-						pool.Priority = int64(priority)
-					}
-
-					if active, ok := poolMap["active"].(bool); ok {
-						pool.Active = active
-					}
+// hiveAPICommand posts a Hive API v2 command to control this worker. These
+// are the operations the local agent doesn't accept directly: the API call
+// updates the flight sheet/OC config and Hive's backend pushes it down to
+// the rig on its next check-in.
+func (s *hiveOSSession) hiveAPICommand(ctx context.Context, command string, args map[string]interface{}) error {
+	if !s.cfg.hasAPIAccess() {
+		return NewPermanentError("hive API not configured", "missing farm_id/worker_id/api_token in Endpoint.Credentials", nil)
+	}
+	s.ensureClient()
 
-					pools = append(pools, pool)
-				}
-			}
-		}
+	payload := map[string]interface{}{"command": command}
+	for k, v := range args {
+		payload[k] = v
+	}
+	body, _ := json.Marshal(payload)
 
-		return pools, nil
+	url := fmt.Sprintf("%s/farms/%s/workers/%s/command", hiveAPIBase, s.cfg.FarmID, s.cfg.WorkerID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return NewTransientError("failed to build hive API request", err.Error(), err)
 	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.cfg.APIToken)
 
-	return nil, NewDeviceError("pools not available", "no working HiveOS pools endpoint found", nil)
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return NewTransientError("hive API unreachable", err.Error(), err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return NewPermanentError("hive API rejected credentials", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	case resp.StatusCode >= 400:
+		return NewTransientError("hive API command failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	default:
+		return nil
+	}
 }
 
 func (s *hiveOSSession) AddPool(ctx context.Context, url, user, pass string) error {
-	return NewDeviceError("add pool not implemented", "HiveOS pool management not yet implemented", nil)
+	return s.hiveAPICommand(ctx, "add_pool", map[string]interface{}{"url": url, "user": user, "pass": pass})
 }
 
 func (s *hiveOSSession) EnablePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("enable pool not implemented", "HiveOS pool management not yet implemented", nil)
+	return s.hiveAPICommand(ctx, "enable_pool", map[string]interface{}{"pool_id": poolID})
 }
 
 func (s *hiveOSSession) DisablePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("disable pool not implemented", "HiveOS pool management not yet implemented", nil)
+	return s.hiveAPICommand(ctx, "disable_pool", map[string]interface{}{"pool_id": poolID})
 }
 
 func (s *hiveOSSession) RemovePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("remove pool not implemented", "HiveOS pool management not yet implemented", nil)
+	return s.hiveAPICommand(ctx, "remove_pool", map[string]interface{}{"pool_id": poolID})
 }
 
 func (s *hiveOSSession) SwitchPool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("switch pool not implemented", "HiveOS pool management not yet implemented", nil)
+	return s.hiveAPICommand(ctx, "switch_pool", map[string]interface{}{"pool_id": poolID})
+}
+
+func (s *hiveOSSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return NewDeviceError("update pool not implemented", "Hive API has no generic pool update command", ErrNotImplemented)
 }
 
 func (s *hiveOSSession) Restart(ctx context.Context) error {
 	s.ensureClient()
-
-	// Try HiveOS-specific restart endpoints
-	endpoints := []string{
-		"/hive/v1/restart",
-		"/api/v1/restart",
-		"/hiveos/restart",
-		"/agent/restart",
-		"/api/restart",
+	url := fmt.Sprintf("http://%s/restart", s.address)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return NewTransientError("failed to build restart request", err.Error(), err)
 	}
-
-	for _, endpoint := range endpoints {
-		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
-		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
-		resp, err := s.httpClient.Do(req)
-		if err != nil {
-			continue
-		}
-		resp.Body.Close()
-
-		if resp.StatusCode < 400 {
-			return nil // Success
-		}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return NewTransientError("hive-client agent unreachable", err.Error(), err)
 	}
-
-	return NewDeviceError("restart failed", "no working HiveOS restart endpoint found", nil)
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return NewTransientError("restart failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	}
+	return nil
 }
 
 func (s *hiveOSSession) Quit(ctx context.Context) error {
-	return NewDeviceError("quit not applicable", "HiveOS does not support quit command", nil)
+	s.ensureClient()
+	url := fmt.Sprintf("http://%s/miner/stop", s.address)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return NewTransientError("failed to build stop request", err.Error(), err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return NewTransientError("hive-client agent unreachable", err.Error(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return NewTransientError("stop failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	}
+	return nil
 }
 
 func (s *hiveOSSession) Exec(ctx context.Context, command string, parameter string) ([]byte, error) {
-	return nil, NewDeviceError("exec not supported", "HiveOS does not support raw command execution", nil)
+	return nil, NewPermanentError("exec not supported", "hive-client does not expose raw command execution", nil)
 }
 
 func (s *hiveOSSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
-	return PowerMode{Kind: PowerBalanced}, NewDeviceError("power mode not implemented", "HiveOS power mode reading not yet implemented", nil)
+	return PowerMode{Kind: PowerBalanced}, NewPermanentError("power mode reading not supported", "hive-client does not expose current OC settings locally", nil)
 }
 
 func (s *hiveOSSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
-	return NewDeviceError("power mode setting not implemented", "HiveOS power mode control not yet implemented", nil)
+	args := map[string]interface{}{"mode": string(mode.Kind)}
+	if mode.Watts > 0 {
+		args["power_limit"] = mode.Watts
+	}
+	if mode.FreqMHz > 0 {
+		args["freq"] = mode.FreqMHz
+	}
+	if mode.VoltageMv > 0 {
+		args["voltage"] = mode.VoltageMv
+	}
+	return s.hiveAPICommand(ctx, "set_oc", args)
 }
 
 func (s *hiveOSSession) GetFan(ctx context.Context) (FanConfig, error) {
-	return FanConfig{Mode: FanAuto}, NewDeviceError("fan control not implemented", "HiveOS fan reading not yet implemented", nil)
+	return FanConfig{Mode: FanAuto}, NewPermanentError("fan reading not supported", "hive-client does not expose current fan settings locally", nil)
 }
 
 func (s *hiveOSSession) SetFan(ctx context.Context, fan FanConfig) error {
-	return NewDeviceError("fan control not implemented", "HiveOS fan control not yet implemented", nil)
+	args := map[string]interface{}{"mode": string(fan.Mode)}
+	if fan.Mode == FanManual {
+		args["speed"] = fan.SpeedPct
+	}
+	return s.hiveAPICommand(ctx, "set_fan", args)
 }
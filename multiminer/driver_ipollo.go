@@ -1,21 +1,34 @@
 package multiminer
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/x1unix/go-cgminer-api/multiminer/models"
 )
 
 // Driver stub for iPollo.
-type ipolloDriver struct{}
+type ipolloDriver struct {
+	opt SessionOptions
+}
+
+func NewIPolloDriver() Driver { return &ipolloDriver{} }
+
+// NewIPolloDriverWithOptions creates an iPollo driver whose sessions share
+// an HTTP client built from opt (timeouts, TLS) and retry every request per
+// opt's retry policy.
+func NewIPolloDriverWithOptions(opt SessionOptions) Driver {
+	return &ipolloDriver{opt: opt}
+}
 
-func NewIPolloDriver() Driver        { return &ipolloDriver{} }
 func (d *ipolloDriver) Name() string { return "ipollo" }
 func (d *ipolloDriver) Capabilities() Capability {
-	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
+	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, AtomicPoolReplaceOnly: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
 }
 
 func (d *ipolloDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
@@ -56,18 +69,19 @@ func (d *ipolloDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 }
 
 func (d *ipolloDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
-	return &ipolloSession{address: ep.Address}, nil
+	return &ipolloSession{address: ep.Address, opt: d.opt}, nil
 }
 
 // ipolloSession implements Session for iPollo devices
 type ipolloSession struct {
 	address    string
+	opt        SessionOptions
 	httpClient *http.Client
 }
 
 func (s *ipolloSession) ensureClient() {
 	if s.httpClient == nil {
-		s.httpClient = &http.Client{Timeout: 3 * time.Second}
+		s.httpClient = s.opt.client()
 	}
 }
 
@@ -82,7 +96,7 @@ func (s *ipolloSession) Model(ctx context.Context) (Model, error) {
 	for _, endpoint := range endpoints {
 		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 		if err != nil {
 			continue
 		}
@@ -100,12 +114,21 @@ func (s *ipolloSession) Model(ctx context.Context) (Model, error) {
 		model := Model{Vendor: "iPollo", Product: "Unknown", Firmware: "Unknown"}
 
 		// Extract model information
+		var descriptor string
 		if minerType, ok := result["miner_type"].(string); ok {
-			model.Product = minerType
+			descriptor = minerType
 		} else if hw, ok := result["hardware"].(string); ok {
-			model.Product = hw
+			descriptor = hw
 		} else if model_name, ok := result["model"].(string); ok {
-			model.Product = model_name
+			descriptor = model_name
+		}
+
+		if descriptor != "" {
+			if m, found := models.MatchIPollo(descriptor); found {
+				model.Product = m.Name
+			} else {
+				model.Product = descriptor
+			}
 		}
 
 		if fw, ok := result["firmware"].(string); ok {
@@ -130,7 +153,7 @@ func (s *ipolloSession) Stats(ctx context.Context) (Stats, error) {
 	for _, endpoint := range endpoints {
 		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 		if err != nil {
 			continue
 		}
@@ -149,14 +172,14 @@ func (s *ipolloSession) Stats(ctx context.Context) (Stats, error) {
 
 		// Extract hashrate information
 		if hashrate, ok := result["hashrate"].(float64); ok {
-			stats.HashrateAv = hashrate / 1000000000 // Convert to GH/s
-			stats.Hashrate5s = stats.HashrateAv      // Use same value for 5s
-		} else if hashrateStr, ok := result["hashrate"].(string); ok {
-			// Parse hashrate string if needed
-			var hr float64
-			fmt.Sscanf(hashrateStr, "%f", &hr)
-			stats.HashrateAv = hr / 1000000000
+			stats.HashrateAv = HashrateValue(hashrate) // result is already in H/s
 			stats.Hashrate5s = stats.HashrateAv
+		} else if hashrateStr, ok := result["hashrate"].(string); ok {
+			// iPollo reports this one as a unit string, e.g. "1.2 TH/s".
+			if hr, err := ParseHashrateString(hashrateStr); err == nil {
+				stats.HashrateAv = hr
+				stats.Hashrate5s = hr
+			}
 		}
 
 		// Extract temperature
@@ -206,7 +229,7 @@ func (s *ipolloSession) Summary(ctx context.Context) (Summary, error) {
 	for _, endpoint := range endpoints {
 		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 		if err != nil {
 			continue
 		}
@@ -244,7 +267,7 @@ func (s *ipolloSession) Pools(ctx context.Context) ([]Pool, error) {
 	for _, endpoint := range endpoints {
 		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 		if err != nil {
 			continue
 		}
@@ -293,24 +316,99 @@ func (s *ipolloSession) Pools(ctx context.Context) ([]Pool, error) {
 	return nil, NewDeviceError("pools not available", "no working iPollo pools endpoint found", nil)
 }
 
+// ipolloPoolEntry is the JSON shape iPollo firmware accepts for POST
+// /api/pools: the whole pool list, replaced atomically.
+type ipolloPoolEntry struct {
+	URL      string `json:"url"`
+	User     string `json:"user"`
+	Pass     string `json:"pass"`
+	Priority int64  `json:"priority"`
+}
+
+// AddPool reads the device's current pool list, appends url/user/pass, and
+// POSTs the whole list back to /api/pools: iPollo firmware has no per-entry
+// add command, only a full-list replace.
 func (s *ipolloSession) AddPool(ctx context.Context, url, user, pass string) error {
-	return NewDeviceError("add pool not implemented", "iPollo pool management not yet implemented", nil)
+	existing, err := s.Pools(ctx)
+	if err != nil {
+		return err
+	}
+
+	merged := mergePoolChange(existing, poolOpAdd, Pool{URL: url, User: user})
+	entries := s.poolsToEntries(merged)
+	if n := len(entries); n > 0 {
+		// merged []Pool carries no password field, so splice the real one
+		// into the entry mergePoolChange just appended.
+		entries[n-1].Pass = pass
+	}
+	return s.putPoolEntries(ctx, entries)
 }
 
 func (s *ipolloSession) EnablePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("enable pool not implemented", "iPollo pool management not yet implemented", nil)
+	return s.applyPoolOp(ctx, poolOpEnable, poolID)
 }
 
 func (s *ipolloSession) DisablePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("disable pool not implemented", "iPollo pool management not yet implemented", nil)
+	return s.applyPoolOp(ctx, poolOpDisable, poolID)
 }
 
 func (s *ipolloSession) RemovePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("remove pool not implemented", "iPollo pool management not yet implemented", nil)
+	return s.applyPoolOp(ctx, poolOpRemove, poolID)
 }
 
 func (s *ipolloSession) SwitchPool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("switch pool not implemented", "iPollo pool management not yet implemented", nil)
+	return s.applyPoolOp(ctx, poolOpSwitch, poolID)
+}
+
+func (s *ipolloSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return NewDeviceError("update pool not implemented", "iPollo's full-list pool replace has no conflict detection to update safely", ErrNotImplemented)
+}
+
+// applyPoolOp reads the device's current pool list, applies op via
+// mergePoolChange, and resubmits the whole list to /api/pools. Existing
+// entries' passwords aren't known (Pools doesn't return them) so they're
+// resubmitted blank, same as AddPool does for the entries it keeps.
+func (s *ipolloSession) applyPoolOp(ctx context.Context, op poolOp, poolID int64) error {
+	existing, err := s.Pools(ctx)
+	if err != nil {
+		return err
+	}
+	merged := mergePoolChange(existing, op, Pool{ID: poolID})
+	return s.putPoolEntries(ctx, s.poolsToEntries(merged))
+}
+
+func (s *ipolloSession) poolsToEntries(pools []Pool) []ipolloPoolEntry {
+	entries := make([]ipolloPoolEntry, 0, len(pools))
+	for _, p := range pools {
+		entries = append(entries, ipolloPoolEntry{URL: p.URL, User: p.User, Priority: p.Priority})
+	}
+	return entries
+}
+
+// putPoolEntries POSTs entries as the device's new, complete pool list.
+func (s *ipolloSession) putPoolEntries(ctx context.Context, entries []ipolloPoolEntry) error {
+	s.ensureClient()
+
+	body, err := json.Marshal(struct {
+		Pools []ipolloPoolEntry `json:"pools"`
+	}{Pools: entries})
+	if err != nil {
+		return NewDeviceError("failed to encode pools", "", err)
+	}
+
+	url := fmt.Sprintf("http://%s/api/pools", s.address)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
+	if err != nil {
+		return NewConnectionError("failed to update pools", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return NewDeviceError("update pools failed", fmt.Sprintf("status %d", resp.StatusCode), nil)
+	}
+	return nil
 }
 
 func (s *ipolloSession) Restart(ctx context.Context) error {
@@ -322,7 +420,7 @@ func (s *ipolloSession) Restart(ctx context.Context) error {
 	for _, endpoint := range endpoints {
 		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
-		resp, err := s.httpClient.Do(req)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 		if err != nil {
 			continue
 		}
@@ -345,7 +443,47 @@ func (s *ipolloSession) Exec(ctx context.Context, command string, parameter stri
 }
 
 func (s *ipolloSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
-	return PowerMode{Kind: PowerBalanced}, NewDeviceError("power mode not implemented", "iPollo power mode reading not yet implemented", nil)
+	s.ensureClient()
+
+	endpoints := []string{"/api/status", "/cgi-bin/status", "/status"}
+	for _, endpoint := range endpoints {
+		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
+		if err != nil {
+			continue
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			continue
+		}
+
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			continue
+		}
+
+		mode := PowerMode{Kind: PowerBalanced}
+		if powerMode, ok := result["power_mode"].(string); ok {
+			switch strings.ToLower(powerMode) {
+			case "low", "eco":
+				mode.Kind = PowerLow
+			case "high", "turbo", "performance":
+				mode.Kind = PowerHigh
+			case "custom":
+				mode.Kind = PowerCustom
+			}
+		}
+		if power, ok := result["power_consumption"].(float64); ok {
+			mode.Watts = int(power)
+		} else if power, ok := result["power"].(float64); ok {
+			mode.Watts = int(power)
+		}
+		return mode, nil
+	}
+
+	return PowerMode{Kind: PowerBalanced}, NewDeviceError("power mode not available", "no working iPollo status endpoint found", nil)
 }
 
 func (s *ipolloSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
@@ -353,7 +491,42 @@ func (s *ipolloSession) SetPowerMode(ctx context.Context, mode PowerMode) error
 }
 
 func (s *ipolloSession) GetFan(ctx context.Context) (FanConfig, error) {
-	return FanConfig{Mode: FanAuto}, NewDeviceError("fan control not implemented", "iPollo fan reading not yet implemented", nil)
+	s.ensureClient()
+
+	endpoints := []string{"/api/status", "/cgi-bin/status", "/status"}
+	for _, endpoint := range endpoints {
+		url := fmt.Sprintf("http://%s%s", s.address, endpoint)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
+		if err != nil {
+			continue
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			continue
+		}
+
+		var result map[string]interface{}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			continue
+		}
+
+		fan := FanConfig{Mode: FanAuto}
+		if mode, ok := result["fan_mode"].(string); ok && strings.EqualFold(mode, "manual") {
+			fan.Mode = FanManual
+		}
+		if speed, ok := result["fan_speed"].(float64); ok {
+			fan.SpeedPct = int(speed)
+		} else if fans, ok := result["fans"].([]interface{}); ok && len(fans) > 0 {
+			if speed, ok := fans[0].(float64); ok {
+				fan.SpeedPct = int(speed)
+			}
+		}
+		return fan, nil
+	}
+
+	return FanConfig{Mode: FanAuto}, NewDeviceError("fan control not available", "no working iPollo status endpoint found", nil)
 }
 
 func (s *ipolloSession) SetFan(ctx context.Context, fan FanConfig) error {
@@ -0,0 +1,80 @@
+package multiminer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIPolloSessionAddPool(t *testing.T) {
+	var posted struct {
+		Pools []ipolloPoolEntry `json:"pools"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/pools", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(r.Body).Decode(&posted); err != nil {
+				t.Fatalf("decode posted pools: %v", err)
+			}
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"pools": []map[string]interface{}{
+				{"url": "stratum+tcp://old:3333", "user": "old.1", "priority": 0, "active": true},
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	sess := &ipolloSession{address: srv.Listener.Addr().String()}
+	if err := sess.AddPool(context.Background(), "stratum+tcp://new:3333", "new.1", "secret"); err != nil {
+		t.Fatalf("AddPool: %v", err)
+	}
+
+	if len(posted.Pools) != 2 {
+		t.Fatalf("expected 2 pools posted, got %d", len(posted.Pools))
+	}
+	newEntry := posted.Pools[1]
+	if newEntry.URL != "stratum+tcp://new:3333" || newEntry.User != "new.1" || newEntry.Pass != "secret" {
+		t.Errorf("unexpected new entry: %+v", newEntry)
+	}
+	if posted.Pools[0].Pass != "" {
+		t.Errorf("expected existing entry's password to be resubmitted blank, got %q", posted.Pools[0].Pass)
+	}
+}
+
+func TestIPolloSessionSwitchPool(t *testing.T) {
+	var posted struct {
+		Pools []ipolloPoolEntry `json:"pools"`
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/pools", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			json.NewDecoder(r.Body).Decode(&posted)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"pools": []map[string]interface{}{
+				{"url": "a", "user": "u", "active": true},
+				{"url": "b", "user": "u", "active": false},
+			},
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	sess := &ipolloSession{address: srv.Listener.Addr().String()}
+	if err := sess.SwitchPool(context.Background(), 1); err != nil {
+		t.Fatalf("SwitchPool: %v", err)
+	}
+	if len(posted.Pools) != 2 {
+		t.Fatalf("expected 2 pools posted, got %d", len(posted.Pools))
+	}
+}
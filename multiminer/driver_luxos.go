@@ -2,6 +2,7 @@ package multiminer
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net"
@@ -12,13 +13,125 @@ import (
 	cg "github.com/x1unix/go-cgminer-api"
 )
 
+// HTTPConfig configures how a Session talks to a device's HTTP API: scheme,
+// TLS verification, client certs, a base path prefix, auth, and an optional
+// caller-supplied RoundTripper for anything this type doesn't cover (custom
+// headers, proxies, mTLS beyond a single cert/key pair). The zero value
+// means "plain HTTP, no auth, no base path" — today's default behavior.
+type HTTPConfig struct {
+	// Scheme is "http" or "https". Empty means "http".
+	Scheme string
+	// BasePath is inserted before each API path, e.g. "/proxy/rig1", for
+	// devices reachable only through a path-routed reverse proxy.
+	BasePath string
+
+	InsecureSkipVerify bool
+	ClientCertFile     string
+	ClientKeyFile      string
+
+	BasicUser   string
+	BasicPass   string
+	BearerToken string
+
+	Timeout time.Duration
+
+	// RoundTripper, if set, is used as-is instead of a Transport built from
+	// the TLS/cert fields above.
+	RoundTripper http.RoundTripper
+}
+
+// IsZero reports whether c is the zero-value HTTPConfig, i.e. no transport
+// customization was requested.
+func (c HTTPConfig) IsZero() bool {
+	return c == HTTPConfig{}
+}
+
+func (c HTTPConfig) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 3 * time.Second
+	}
+	return c.Timeout
+}
+
+func (c HTTPConfig) scheme() string {
+	if c.Scheme == "" {
+		return "http"
+	}
+	return c.Scheme
+}
+
+// client builds an *http.Client honoring c's TLS/cert settings, or wraps
+// c.RoundTripper directly when set.
+func (c HTTPConfig) client() (*http.Client, error) {
+	if c.RoundTripper != nil {
+		return &http.Client{Transport: c.RoundTripper, Timeout: c.timeout()}, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.ClientCertFile != "" || c.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.ClientCertFile, c.ClientKeyFile)
+		if err != nil {
+			return nil, NewConnectionError("failed to load client certificate", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{
+		Timeout:   c.timeout(),
+		Transport: &http.Transport{TLSClientConfig: tlsConfig},
+	}, nil
+}
+
+// buildURL joins c's scheme and BasePath with host and path, e.g.
+// buildURL("10.0.0.5:8080", "/api/v1/stats") -> "http://10.0.0.5:8080/api/v1/stats".
+func (c HTTPConfig) buildURL(host, path string) string {
+	base := c.BasePath
+	if base != "" {
+		path = joinPath(base, path)
+	}
+	return fmt.Sprintf("%s://%s%s", c.scheme(), host, path)
+}
+
+func (c HTTPConfig) applyAuth(req *http.Request) {
+	switch {
+	case c.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	case c.BasicUser != "" || c.BasicPass != "":
+		req.SetBasicAuth(c.BasicUser, c.BasicPass)
+	}
+}
+
+// LuxOSOptions configures a LuxOS driver instance.
+type LuxOSOptions struct {
+	// HTTP configures the driver's HTTP transport. The zero value preserves
+	// today's behavior: plain HTTP, probing a fixed list of candidate ports.
+	// Setting any field switches to talking HTTPS/auth directly to the
+	// endpoint's own address instead of guessing ports, since a configured
+	// transport implies the caller already knows exactly where to reach the
+	// device (e.g. behind a reverse proxy).
+	HTTP HTTPConfig
+}
+
 // Driver stub for LuxOS (Bitmain fork with HTTP APIs)
-type luxOSDriver struct{}
+type luxOSDriver struct {
+	opt LuxOSOptions
+}
+
+func NewLuxOSDriver() Driver { return NewLuxOSDriverWithOptions(LuxOSOptions{}) }
+
+// NewLuxOSDriverWithOptions creates a LuxOS driver using opt.HTTP for every
+// device it opens, so firmware behind TLS-terminating reverse proxies or
+// requiring basic/bearer auth can be reached without forking the driver.
+func NewLuxOSDriverWithOptions(opt LuxOSOptions) Driver {
+	return &luxOSDriver{opt: opt}
+}
 
-func NewLuxOSDriver() Driver { return &luxOSDriver{} }
 func (d *luxOSDriver) Name() string { return "luxos" }
 func (d *luxOSDriver) Capabilities() Capability {
-	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
+	return Capability{
+		ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true,
+		PowerControl: true, FanControl: true, MaxChains: 3, SupportsPerChainTuning: true, TuneHashrate: true,
+	}
 }
 func (d *luxOSDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 	// LuxOS supports both HTTP API and cgminer API
@@ -27,7 +140,7 @@ func (d *luxOSDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 	if httpFound {
 		return true, nil
 	}
-	
+
 	// Try cgminer API detection with LuxOS-specific heuristics
 	c := &cg.CGMiner{
 		Address:   ep.Address,
@@ -35,62 +148,86 @@ func (d *luxOSDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
 		Transport: cg.NewJSONTransport(),
 		Dialer:    &net.Dialer{Timeout: 1200 * time.Millisecond},
 	}
-	
+
 	v, err := c.VersionContext(ctx)
 	if err != nil {
 		return false, nil
 	}
-	
+
 	// Check for LuxOS-specific identifiers
 	joined := strings.ToLower(v.Type + " " + v.Miner + " " + v.BMMiner + " " + v.CompileTime)
-	if strings.Contains(joined, "luxos") || 
-	   strings.Contains(joined, "luxor") ||
-	   (strings.Contains(joined, "bitmain") && strings.Contains(joined, "lux")) {
+	if strings.Contains(joined, "luxos") ||
+		strings.Contains(joined, "luxor") ||
+		(strings.Contains(joined, "bitmain") && strings.Contains(joined, "lux")) {
 		return true, nil
 	}
-	
+
 	return false, nil
 }
 
 func (d *luxOSDriver) detectHTTP(ctx context.Context, address string) bool {
-	// Try common LuxOS HTTP endpoints
 	httpCandidates := []string{"/api/v1/status", "/luxos/api/status", "/api/status"}
-	
+
+	if !d.opt.HTTP.IsZero() {
+		client, err := d.opt.HTTP.client()
+		if err != nil {
+			return false
+		}
+		for _, p := range httpCandidates {
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, d.opt.HTTP.buildURL(address, p), nil)
+			d.opt.HTTP.applyAuth(req)
+			resp, err := client.Do(req)
+			if err != nil {
+				continue
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return true
+			}
+		}
+		return false
+	}
+
 	// Extract host without port, then try common HTTP ports
 	host, _, err := net.SplitHostPort(address)
 	if err != nil {
 		host = address
 	}
-	
+
 	httpAddresses := []string{
 		host + ":8080",
 		host + ":80",
 		host + ":4028", // Some LuxOS installations use this
 	}
-	
+
 	for _, addr := range httpAddresses {
 		if _, found := probeHTTP(ctx, addr, httpCandidates, 800*time.Millisecond); found {
 			return true
 		}
 	}
-	
+
 	return false
 }
 
 func (d *luxOSDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
-	return &luxOSSession{address: ep.Address}, nil
+	return &luxOSSession{address: ep.Address, httpCfg: d.opt.HTTP}, nil
 }
 
 // luxOSSession implements Session for LuxOS devices
 type luxOSSession struct {
 	address    string
+	httpCfg    HTTPConfig
 	httpClient *http.Client
 	cgClient   *cg.CGMiner
 }
 
-func (s *luxOSSession) ensureClients() {
+func (s *luxOSSession) ensureClients() error {
 	if s.httpClient == nil {
-		s.httpClient = &http.Client{Timeout: 3 * time.Second}
+		client, err := s.httpCfg.client()
+		if err != nil {
+			return err
+		}
+		s.httpClient = client
 	}
 	if s.cgClient == nil {
 		s.cgClient = &cg.CGMiner{
@@ -100,186 +237,256 @@ func (s *luxOSSession) ensureClients() {
 			Dialer:    &net.Dialer{Timeout: 3 * time.Second},
 		}
 	}
+	return nil
+}
+
+// httpHosts returns the session's HTTP host:port candidates: the endpoint's
+// own address when a transport was configured (the caller knows exactly
+// where to reach it), or the legacy guessed ports otherwise. The power, fan,
+// ATM and profile calls use only the first entry; Model/Stats lookups build
+// their own host lists.
+func (s *luxOSSession) httpHosts() []string {
+	if !s.httpCfg.IsZero() {
+		return []string{s.address}
+	}
+
+	host, _, err := net.SplitHostPort(s.address)
+	if err != nil {
+		host = s.address
+	}
+	return []string{host + ":8080", host + ":80", host + ":4028"}
+}
+
+func (s *luxOSSession) newRequest(ctx context.Context, method, host, path string, body string) (*http.Request, error) {
+	var reader *strings.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	var req *http.Request
+	var err error
+	if reader != nil {
+		req, err = http.NewRequestWithContext(ctx, method, s.httpCfg.buildURL(host, path), reader)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, s.httpCfg.buildURL(host, path), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.httpCfg.applyAuth(req)
+	return req, nil
 }
 
 func (s *luxOSSession) Close() error { return nil }
 
 func (s *luxOSSession) Model(ctx context.Context) (Model, error) {
-	s.ensureClients()
-	
+	if err := s.ensureClients(); err != nil {
+		return Model{}, err
+	}
+
 	// Try HTTP API first
 	if model, err := s.getModelHTTP(ctx); err == nil {
 		return model, nil
 	}
-	
+
 	// Fallback to cgminer API
 	v, err := s.cgClient.VersionContext(ctx)
 	if err != nil {
 		return Model{}, NewConnectionError("failed to get device model", err)
 	}
-	
+
 	return Model{Vendor: "LuxOS", Product: v.Miner, Firmware: v.BMMiner}, nil
 }
 
 func (s *luxOSSession) getModelHTTP(ctx context.Context) (Model, error) {
-	// Try to find HTTP endpoint
-	host, _, err := net.SplitHostPort(s.address)
-	if err != nil {
-		host = s.address
-	}
-	
-	urls := []string{
-		fmt.Sprintf("http://%s:8080/api/v1/status", host),
-		fmt.Sprintf("http://%s:80/luxos/api/status", host),
-		fmt.Sprintf("http://%s:4028/api/status", host),
-	}
-	
-	for _, url := range urls {
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
+	var hosts, paths []string
+	if s.httpCfg.IsZero() {
+		host, _, err := net.SplitHostPort(s.address)
 		if err != nil {
-			continue
+			host = s.address
 		}
-		defer resp.Body.Close()
-		
-		if resp.StatusCode != http.StatusOK {
+		hosts = []string{host + ":8080", host + ":80", host + ":4028"}
+		paths = []string{"/api/v1/status", "/luxos/api/status", "/api/status"}
+	} else {
+		hosts = []string{s.address}
+		paths = []string{"/api/v1/status"}
+	}
+
+	for i, host := range hosts {
+		req, err := s.newRequest(ctx, http.MethodGet, host, paths[i], "")
+		if err != nil {
 			continue
 		}
-		
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
 			continue
 		}
-		
-		model := Model{Vendor: "LuxOS", Product: "Unknown", Firmware: "LuxOS"}
-		
-		if miner, ok := result["miner_type"].(string); ok {
-			model.Product = miner
-		} else if hw, ok := result["hardware"].(string); ok {
-			model.Product = hw
-		}
-		
-		if fw, ok := result["firmware"].(string); ok {
-			model.Firmware = fw
-		} else if version, ok := result["version"].(string); ok {
-			model.Firmware = "LuxOS " + version
+
+		model, ok := parseModelHTTPResponse(resp)
+		if ok {
+			return model, nil
 		}
-		
-		return model, nil
 	}
-	
+
 	return Model{}, fmt.Errorf("no HTTP endpoint found")
 }
 
+func parseModelHTTPResponse(resp *http.Response) (Model, bool) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Model{}, false
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Model{}, false
+	}
+
+	model := Model{Vendor: "LuxOS", Product: "Unknown", Firmware: "LuxOS"}
+
+	if miner, ok := result["miner_type"].(string); ok {
+		model.Product = miner
+	} else if hw, ok := result["hardware"].(string); ok {
+		model.Product = hw
+	}
+
+	if fw, ok := result["firmware"].(string); ok {
+		model.Firmware = fw
+	} else if version, ok := result["version"].(string); ok {
+		model.Firmware = "LuxOS " + version
+	}
+
+	return model, true
+}
+
 func (s *luxOSSession) Stats(ctx context.Context) (Stats, error) {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return Stats{}, err
+	}
 	model, _ := s.Model(ctx)
-	
+
 	// Try HTTP API first
 	if stats, err := s.getStatsHTTP(ctx, model); err == nil {
 		return stats, nil
 	}
-	
+
 	// Fallback to cgminer API
 	st, err := s.cgClient.StatsContext(ctx)
 	if err != nil {
 		return Stats{Model: model}, NewConnectionError("failed to get stats", err)
 	}
-	
+
 	g := st.Generic()
 	return Stats{
 		Model:      model,
-		Hashrate5s: g.Ghs5s.Float64(),
-		HashrateAv: g.GhsAverage,
+		Hashrate5s: HashrateFromGHS(g.Ghs5s.Float64()),
+		HashrateAv: HashrateFromGHS(g.GhsAverage),
 		TempMax:    float64(g.TempMax),
 		UptimeSec:  g.Elapsed,
 	}, nil
 }
 
 func (s *luxOSSession) getStatsHTTP(ctx context.Context, model Model) (Stats, error) {
-	host, _, err := net.SplitHostPort(s.address)
-	if err != nil {
-		host = s.address
-	}
-	
-	urls := []string{
-		fmt.Sprintf("http://%s:8080/api/v1/stats", host),
-		fmt.Sprintf("http://%s:80/luxos/api/stats", host),
-	}
-	
-	for _, url := range urls {
-		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-		resp, err := s.httpClient.Do(req)
+	var hosts []string
+	var paths []string
+	if s.httpCfg.IsZero() {
+		host, _, err := net.SplitHostPort(s.address)
 		if err != nil {
-			continue
+			host = s.address
 		}
-		defer resp.Body.Close()
-		
-		if resp.StatusCode != http.StatusOK {
+		hosts = []string{host + ":8080", host + ":80"}
+		paths = []string{"/api/v1/stats", "/luxos/api/stats"}
+	} else {
+		hosts = []string{s.address}
+		paths = []string{"/api/v1/stats"}
+	}
+
+	for i, host := range hosts {
+		req, err := s.newRequest(ctx, http.MethodGet, host, paths[i], "")
+		if err != nil {
 			continue
 		}
-		
-		var result map[string]interface{}
-		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		resp, err := s.httpClient.Do(req)
+		if err != nil {
 			continue
 		}
-		
-		stats := Stats{Model: model}
-		
-		if hashrate, ok := result["hashrate_5s"].(float64); ok {
-			stats.Hashrate5s = hashrate / 1000000000 // Convert to GH/s
-		} else if hashrate, ok := result["hashrate"].(float64); ok {
-			stats.Hashrate5s = hashrate / 1000000000
-		}
-		
-		if hashrateAvg, ok := result["hashrate_avg"].(float64); ok {
-			stats.HashrateAv = hashrateAvg / 1000000000
-		} else {
-			stats.HashrateAv = stats.Hashrate5s
-		}
-		
-		if temp, ok := result["temp_max"].(float64); ok {
-			stats.TempMax = temp
-		} else if temp, ok := result["temperature"].(float64); ok {
-			stats.TempMax = temp
-		}
-		
-		if uptime, ok := result["uptime"].(float64); ok {
-			stats.UptimeSec = int64(uptime)
+
+		stats, ok := parseStatsHTTPResponse(resp, model)
+		if ok {
+			return stats, nil
 		}
-		
-		return stats, nil
 	}
-	
+
 	return Stats{}, fmt.Errorf("no HTTP stats endpoint found")
 }
 
+func parseStatsHTTPResponse(resp *http.Response, model Model) (Stats, bool) {
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Stats{}, false
+	}
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Stats{}, false
+	}
+
+	stats := Stats{Model: model}
+
+	if hashrate, ok := result["hashrate_5s"].(float64); ok {
+		stats.Hashrate5s = HashrateValue(hashrate) // result is already in H/s
+	} else if hashrate, ok := result["hashrate"].(float64); ok {
+		stats.Hashrate5s = HashrateValue(hashrate)
+	}
+
+	if hashrateAvg, ok := result["hashrate_avg"].(float64); ok {
+		stats.HashrateAv = HashrateValue(hashrateAvg)
+	} else {
+		stats.HashrateAv = stats.Hashrate5s
+	}
+
+	if temp, ok := result["temp_max"].(float64); ok {
+		stats.TempMax = temp
+	} else if temp, ok := result["temperature"].(float64); ok {
+		stats.TempMax = temp
+	}
+
+	if uptime, ok := result["uptime"].(float64); ok {
+		stats.UptimeSec = int64(uptime)
+	}
+
+	return stats, true
+}
+
 func (s *luxOSSession) Summary(ctx context.Context) (Summary, error) {
-	s.ensureClients()
-	
+	if err := s.ensureClients(); err != nil {
+		return Summary{}, err
+	}
+
 	// Use cgminer API for summary as it's more standardized
 	sm, err := s.cgClient.SummaryContext(ctx)
 	if err != nil {
 		return Summary{}, NewConnectionError("failed to get summary", err)
 	}
-	
+
 	return Summary{
 		Accepted:              sm.Accepted,
 		Rejected:              sm.Rejected,
 		DeviceHardwarePercent: sm.DeviceHardwarePercent,
-		GHS5s:                 sm.GHS5s.Float64(),
-		GHSav:                 sm.GHSav,
+		GHS5s:                 HashrateFromGHS(sm.GHS5s.Float64()),
+		GHSav:                 HashrateFromGHS(sm.GHSav),
 	}, nil
 }
 
 func (s *luxOSSession) Pools(ctx context.Context) ([]Pool, error) {
-	s.ensureClients()
-	
+	if err := s.ensureClients(); err != nil {
+		return nil, err
+	}
+
 	pls, err := s.cgClient.PoolsContext(ctx)
 	if err != nil {
 		return nil, NewConnectionError("failed to get pools", err)
 	}
-	
+
 	out := make([]Pool, 0, len(pls))
 	for _, p := range pls {
 		out = append(out, Pool{ID: p.Pool, URL: p.URL, User: p.User, Priority: p.Priority, Active: p.StratumActive})
@@ -288,72 +495,91 @@ func (s *luxOSSession) Pools(ctx context.Context) ([]Pool, error) {
 }
 
 func (s *luxOSSession) AddPool(ctx context.Context, url, user, pass string) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.AddPoolContext(ctx, url, user, pass)
 }
 
 func (s *luxOSSession) EnablePool(ctx context.Context, poolID int64) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.EnablePoolContext(ctx, &cg.Pool{Pool: poolID})
 }
 
 func (s *luxOSSession) DisablePool(ctx context.Context, poolID int64) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.DisablePoolContext(ctx, &cg.Pool{Pool: poolID})
 }
 
 func (s *luxOSSession) RemovePool(ctx context.Context, poolID int64) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.CallContext(ctx, cg.NewCommand("removepool", fmt.Sprint(poolID)), nil)
 }
 
 func (s *luxOSSession) SwitchPool(ctx context.Context, poolID int64) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.CallContext(ctx, cg.NewCommand("switchpool", fmt.Sprint(poolID)), nil)
 }
 
+func (s *luxOSSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return NewDeviceError("update pool not implemented", "LuxOS may not support in-place pool updates", ErrNotImplemented)
+}
+
 func (s *luxOSSession) Restart(ctx context.Context) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.CallContext(ctx, cg.NewCommandWithoutParameter("restart"), nil)
 }
 
 func (s *luxOSSession) Quit(ctx context.Context) error {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
 	return s.cgClient.CallContext(ctx, cg.NewCommandWithoutParameter("quit"), nil)
 }
 
 func (s *luxOSSession) Exec(ctx context.Context, command string, parameter string) ([]byte, error) {
-	s.ensureClients()
+	if err := s.ensureClients(); err != nil {
+		return nil, err
+	}
 	return s.cgClient.RawCall(ctx, cg.NewCommand(command, parameter))
 }
 
 // Power management - LuxOS supports advanced power tuning
 func (s *luxOSSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
-	s.ensureClients()
-	
-	// Try HTTP API for power mode
-	host, _, err := net.SplitHostPort(s.address)
+	if err := s.ensureClients(); err != nil {
+		return PowerMode{Kind: PowerBalanced}, err
+	}
+
+	host := s.httpHosts()[0]
+	req, err := s.newRequest(ctx, http.MethodGet, host, "/api/v1/power", "")
 	if err != nil {
-		host = s.address
+		return PowerMode{Kind: PowerBalanced}, NewDeviceError("power mode not available", "failed building request", err)
 	}
-	
-	url := fmt.Sprintf("http://%s:8080/api/v1/power", host)
-	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return PowerMode{Kind: PowerBalanced}, NewDeviceError("power mode not available", "HTTP API not accessible", err)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode != http.StatusOK {
 		return PowerMode{Kind: PowerBalanced}, nil // Default fallback
 	}
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return PowerMode{Kind: PowerBalanced}, nil
 	}
-	
+
 	mode := PowerMode{Kind: PowerBalanced}
 	if modeStr, ok := result["mode"].(string); ok {
 		switch strings.ToLower(modeStr) {
@@ -367,88 +593,258 @@ func (s *luxOSSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
 			mode.Kind = PowerBalanced
 		}
 	}
-	
+
 	if watts, ok := result["watts"].(float64); ok {
 		mode.Watts = int(watts)
 	}
-	
+
 	return mode, nil
 }
 
 func (s *luxOSSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
-	s.ensureClients()
-	
-	host, _, err := net.SplitHostPort(s.address)
-	if err != nil {
-		host = s.address
+	if err := s.ensureClients(); err != nil {
+		return err
 	}
-	
-	// Try HTTP API
-	url := fmt.Sprintf("http://%s:8080/api/v1/power", host)
-	
+
 	payload := map[string]interface{}{
 		"mode": string(mode.Kind),
 	}
-	
 	if mode.Watts > 0 {
 		payload["watts"] = mode.Watts
 	}
-	
 	jsonData, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonData)))
+
+	host := s.httpHosts()[0]
+	req, err := s.newRequest(ctx, http.MethodPost, host, "/api/v1/power", string(jsonData))
+	if err != nil {
+		return NewDeviceError("power mode setting failed", "failed building request", err)
+	}
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return NewDeviceError("power mode setting failed", "HTTP API not accessible", err)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode >= 400 {
 		return NewDeviceError("power mode setting failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
 	}
-	
+
 	return nil
 }
 
 func (s *luxOSSession) GetFan(ctx context.Context) (FanConfig, error) {
-	s.ensureClients()
-	
+	if err := s.ensureClients(); err != nil {
+		return FanConfig{}, err
+	}
+
 	// Default to auto mode
 	return FanConfig{Mode: FanAuto}, nil
 }
 
 func (s *luxOSSession) SetFan(ctx context.Context, fan FanConfig) error {
-	s.ensureClients()
-	
-	host, _, err := net.SplitHostPort(s.address)
-	if err != nil {
-		host = s.address
+	if err := s.ensureClients(); err != nil {
+		return err
 	}
-	
-	url := fmt.Sprintf("http://%s:8080/api/v1/fans", host)
-	
+
 	payload := map[string]interface{}{
 		"mode": string(fan.Mode),
 	}
-	
 	if fan.Mode == FanManual {
 		payload["speed"] = fan.SpeedPct
 	}
-	
 	jsonData, _ := json.Marshal(payload)
-	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(string(jsonData)))
+
+	host := s.httpHosts()[0]
+	req, err := s.newRequest(ctx, http.MethodPost, host, "/api/v1/fans", string(jsonData))
+	if err != nil {
+		return NewDeviceError("fan control failed", "failed building request", err)
+	}
 	req.Header.Set("Content-Type", "application/json")
-	
+
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
 		return NewDeviceError("fan control failed", "HTTP API not accessible", err)
 	}
 	defer resp.Body.Close()
-	
+
 	if resp.StatusCode >= 400 {
 		return NewDeviceError("fan control failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
 	}
-	
+
+	return nil
+}
+
+// Profile bundles LuxOS ATM (Advanced Thermal Management) tuning beyond a
+// flat PowerMode: per-hashboard frequency/voltage overrides, a target
+// efficiency, and the thermal envelope the firmware's autotuner should stay
+// within.
+type Profile struct {
+	Chains []ChainTuning
+	// TargetEfficiencyJTH is the target efficiency in joules per terahash;
+	// 0 means unset/firmware default.
+	TargetEfficiencyJTH float64
+	// MaxTempC is the thermal envelope ceiling in Celsius the autotuner
+	// should stay under; 0 means unset/firmware default.
+	MaxTempC float64
+}
+
+// LuxOSTuner is implemented by LuxOS sessions, exposing ATM-specific tuning
+// beyond the generic Session.SetPowerMode/SetFan. Callers type-assert a
+// Session to this interface after checking Capability.TuneHashrate.
+type LuxOSTuner interface {
+	SetHashrateTarget(ctx context.Context, ths float64) error
+	SetPowerLimit(ctx context.Context, watts int) error
+	GetProfile(ctx context.Context) (Profile, error)
+	SetProfile(ctx context.Context, p Profile) error
+}
+
+var _ LuxOSTuner = (*luxOSSession)(nil)
+
+// SetHashrateTarget asks the ATM autotuner to chase a specific hashrate.
+func (s *luxOSSession) SetHashrateTarget(ctx context.Context, ths float64) error {
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
+	return s.postATM(ctx, map[string]interface{}{"target_ths": ths})
+}
+
+// SetPowerLimit caps the device's power draw via the ATM autotuner.
+func (s *luxOSSession) SetPowerLimit(ctx context.Context, watts int) error {
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
+	return s.postATM(ctx, map[string]interface{}{"power_limit_watts": watts})
+}
+
+func (s *luxOSSession) postATM(ctx context.Context, payload map[string]interface{}) error {
+	jsonData, _ := json.Marshal(payload)
+	host := s.httpHosts()[0]
+	req, err := s.newRequest(ctx, http.MethodPost, host, "/api/v1/atm", string(jsonData))
+	if err != nil {
+		return NewDeviceError("atm tuning failed", "failed building request", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return NewDeviceError("atm tuning failed", "HTTP API not accessible", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return NewDeviceError("atm tuning failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// GetProfile reads back the current ATM tuning profile over HTTP; LuxOS
+// doesn't expose per-chain read-back over the cgminer API, so unlike
+// SetProfile there's no cgminer fallback here.
+func (s *luxOSSession) GetProfile(ctx context.Context) (Profile, error) {
+	if err := s.ensureClients(); err != nil {
+		return Profile{}, err
+	}
+
+	host := s.httpHosts()[0]
+	req, err := s.newRequest(ctx, http.MethodGet, host, "/api/v1/profile", "")
+	if err != nil {
+		return Profile{}, NewConnectionError("failed to get profile", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return Profile{}, NewConnectionError("failed to get profile", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Profile{}, NewDeviceError("failed to get profile", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	}
+
+	var result struct {
+		Chains []struct {
+			Index     int  `json:"index"`
+			FreqMHz   int  `json:"freq_mhz"`
+			VoltageMv int  `json:"voltage_mv"`
+			Enabled   bool `json:"enabled"`
+		} `json:"chains"`
+		TargetEfficiencyJTH float64 `json:"target_efficiency_j_th"`
+		MaxTempC            float64 `json:"max_temp_c"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Profile{}, NewDeviceError("failed to decode profile", err.Error(), err)
+	}
+
+	p := Profile{TargetEfficiencyJTH: result.TargetEfficiencyJTH, MaxTempC: result.MaxTempC}
+	for _, c := range result.Chains {
+		p.Chains = append(p.Chains, ChainTuning{Index: c.Index, FreqMHz: c.FreqMHz, VoltageMv: c.VoltageMv, Enabled: c.Enabled})
+	}
+	return p, nil
+}
+
+// SetProfile applies p via the LuxOS HTTP profile API, falling back to
+// per-chain cgminer `ascset` frequency/voltage commands if the HTTP API is
+// unavailable.
+func (s *luxOSSession) SetProfile(ctx context.Context, p Profile) error {
+	if err := s.ensureClients(); err != nil {
+		return err
+	}
+
+	if err := s.setProfileHTTP(ctx, p); err == nil {
+		return nil
+	}
+	return s.setProfileASCSet(ctx, p)
+}
+
+func (s *luxOSSession) setProfileHTTP(ctx context.Context, p Profile) error {
+	chains := make([]map[string]interface{}, 0, len(p.Chains))
+	for _, c := range p.Chains {
+		chains = append(chains, map[string]interface{}{
+			"index": c.Index, "freq_mhz": c.FreqMHz, "voltage_mv": c.VoltageMv, "enabled": c.Enabled,
+		})
+	}
+	payload := map[string]interface{}{"chains": chains}
+	if p.TargetEfficiencyJTH > 0 {
+		payload["target_efficiency_j_th"] = p.TargetEfficiencyJTH
+	}
+	if p.MaxTempC > 0 {
+		payload["max_temp_c"] = p.MaxTempC
+	}
+	jsonData, _ := json.Marshal(payload)
+
+	host := s.httpHosts()[0]
+	req, err := s.newRequest(ctx, http.MethodPost, host, "/api/v1/profile", string(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return NewDeviceError("profile setting failed", fmt.Sprintf("HTTP %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// setProfileASCSet applies per-chain frequency/voltage via the cgminer
+// `ascset` command, LuxOS's documented fallback for firmware builds without
+// the HTTP profile API.
+func (s *luxOSSession) setProfileASCSet(ctx context.Context, p Profile) error {
+	for _, c := range p.Chains {
+		freqParam := fmt.Sprintf("%d,freq,%d", c.Index, c.FreqMHz)
+		if err := s.cgClient.CallContext(ctx, cg.NewCommand("ascset", freqParam), nil); err != nil {
+			return NewDeviceError("ascset frequency failed", freqParam, err)
+		}
+		voltParam := fmt.Sprintf("%d,volt,%d", c.Index, c.VoltageMv)
+		if err := s.cgClient.CallContext(ctx, cg.NewCommand("ascset", voltParam), nil); err != nil {
+			return NewDeviceError("ascset voltage failed", voltParam, err)
+		}
+	}
 	return nil
 }
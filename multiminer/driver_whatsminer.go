@@ -1,81 +1,123 @@
 package multiminer
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/aes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
-	"regexp"
+	neturl "net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/x1unix/go-cgminer-api/multiminer/models"
 )
-// Whatsminer driver using MicroBT HTTP API (skeleton)
-type whatsminerDriver struct{}
+
+// Whatsminer driver using MicroBT HTTP API, falling back to the TCP mm API
+// (see mmCall and friends below) for firmware that doesn't expose one.
+type whatsminerDriver struct {
+	opt SessionOptions
+}
 
 func NewWhatsminerDriver() Driver { return &whatsminerDriver{} }
+
+// NewWhatsminerDriverWithOptions creates a Whatsminer driver whose sessions
+// share an HTTP client built from opt (timeouts, TLS) and retry every
+// request per opt's retry policy.
+func NewWhatsminerDriverWithOptions(opt SessionOptions) Driver {
+	return &whatsminerDriver{opt: opt}
+}
+
 func (d *whatsminerDriver) Name() string { return "whatsminer" }
 func (d *whatsminerDriver) Capabilities() Capability {
-	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
+	return Capability{ReadStats: true, ReadSummary: true, ListPools: true, ManagePools: true, AtomicPoolReplaceOnly: true, Restart: true, Quit: true, PowerControl: true, FanControl: true}
 }
 
 func (d *whatsminerDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
-	// Preference: fastest/lightest first. Try HTTP probe, then fall back (e.g., TCP if available).
+	// Preference: fastest/lightest first. Try HTTP probe, then the mm API.
 	if _, ok := probeHTTP(ctx, ep.Address, []string{"/cgi-bin/minerStatus.cgi", "/api/status", "/"}, 1200*time.Millisecond); ok {
 		return true, nil
 	}
-	// TODO: Try TCP probe to Whatsminer mm API (secondary) if needed.
+
+	probeCtx, cancel := context.WithTimeout(ctx, 1200*time.Millisecond)
+	defer cancel()
+	if _, err := mmReadCmd(probeCtx, ep.Address, "status"); err == nil {
+		return true, nil
+	}
 	return false, nil
 }
 
 func (d *whatsminerDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
-	// Decide protocol at open time: prefer HTTP if available, else fallback.
+	// Decide protocol at open time: prefer HTTP if available, else the mm
+	// API. The session remembers which one won and falls back per-call if
+	// HTTP later stops answering.
+	sess := &whatsminerSession{addr: ep.Address, password: ep.Credentials["admin_password"], opt: d.opt}
 	if path, ok := probeHTTP(ctx, ep.Address, []string{"/api/status", "/cgi-bin/minerStatus.cgi"}, 1200*time.Millisecond); ok {
-		return &whatsminerSession{addr: ep.Address, basePath: path, useHTTP: true}, nil
+		sess.basePath = path
+		sess.useHTTP = true
 	}
-	// Fallback: TCP or alternative
-	return &whatsminerSession{addr: ep.Address, useHTTP: false}, nil
+	return sess, nil
 }
 
-type whatsminerSession struct{ 
+type whatsminerSession struct {
 	addr       string
-	basePath   string 
+	basePath   string
 	useHTTP    bool
+	password   string // admin password; derives the mm API write token
+	opt        SessionOptions
 	httpClient *http.Client
 }
 
 func (s *whatsminerSession) ensureClient() {
 	if s.httpClient == nil {
-		s.httpClient = &http.Client{Timeout: 3 * time.Second}
+		s.httpClient = s.opt.client()
 	}
 }
 
 func (s *whatsminerSession) Close() error { return nil }
 
+// fellBackToTCP records that an HTTP call failed mid-session, so every
+// subsequent call on this session goes straight to the mm API instead of
+// probing HTTP again. GetPowerMode has no mm API path and returns an error
+// once the session has fallen back.
+func (s *whatsminerSession) fellBackToTCP() { s.useHTTP = false }
+
 func (s *whatsminerSession) Model(ctx context.Context) (Model, error) {
-	if !s.useHTTP {
-		return Model{Vendor: "MicroBT", Product: "Whatsminer", Firmware: "Unknown"}, nil
+	if s.useHTTP {
+		if model, err := s.modelHTTP(ctx); err == nil {
+			return model, nil
+		}
+		s.fellBackToTCP()
 	}
-	
+	return s.modelTCP(ctx)
+}
+
+func (s *whatsminerSession) modelHTTP(ctx context.Context) (Model, error) {
 	s.ensureClient()
-	
-	// Try to get device info from status endpoint
+
 	url := fmt.Sprintf("http://%s%s", s.addr, s.basePath)
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := s.httpClient.Do(req)
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 	if err != nil {
-		return Model{Vendor: "MicroBT", Product: "Whatsminer", Firmware: "Unknown"}, nil
+		return Model{}, NewConnectionError("failed to get device model", err)
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return Model{Vendor: "MicroBT", Product: "Whatsminer", Firmware: "Unknown"}, nil
+		return Model{}, NewDeviceError("failed to parse model", "invalid JSON response", err)
 	}
-	
+
 	model := Model{Vendor: "MicroBT", Product: "Whatsminer", Firmware: "Unknown"}
-	
+
 	// Try to extract model information
 	if minerType, ok := result["miner_type"].(string); ok {
 		if m, found := models.MatchWhatsminer(minerType); found {
@@ -86,55 +128,87 @@ func (s *whatsminerSession) Model(ctx context.Context) (Model, error) {
 	} else if hw, ok := result["hardware"].(string); ok {
 		model.Product = hw
 	}
-	
+
 	if fw, ok := result["firmware"].(string); ok {
 		model.Firmware = fw
 	} else if version, ok := result["version"].(string); ok {
 		model.Firmware = version
 	}
-	
+
+	return model, nil
+}
+
+// modelTCP derives Model from the mm API's "version" command.
+func (s *whatsminerSession) modelTCP(ctx context.Context) (Model, error) {
+	resp, err := mmReadCmd(ctx, s.addr, "version")
+	if err != nil {
+		return Model{}, err
+	}
+
+	model := Model{Vendor: "MicroBT", Product: "Whatsminer", Firmware: "Unknown"}
+	for _, entry := range mmEntries(resp, "VERSION") {
+		if minerType, ok := entry["Type"].(string); ok {
+			if m, found := models.MatchWhatsminer(minerType); found {
+				model.Product = m.Name
+			} else {
+				model.Product = minerType
+			}
+		}
+		if fw, ok := entry["MSBCTRL"].(string); ok {
+			model.Firmware = fw
+		} else if fw, ok := entry["CGMiner"].(string); ok {
+			model.Firmware = fw
+		}
+	}
 	return model, nil
 }
 
 func (s *whatsminerSession) Stats(ctx context.Context) (Stats, error) {
-	if !s.useHTTP {
-		return Stats{}, NewDeviceError("stats not available", "TCP mode not implemented", nil)
+	model, _ := s.Model(ctx)
+
+	if s.useHTTP {
+		if stats, err := s.statsHTTP(ctx, model); err == nil {
+			return stats, nil
+		}
+		s.fellBackToTCP()
 	}
-	
+	return s.statsTCP(ctx, model)
+}
+
+func (s *whatsminerSession) statsHTTP(ctx context.Context, model Model) (Stats, error) {
 	s.ensureClient()
-	model, _ := s.Model(ctx)
-	
+
 	url := fmt.Sprintf("http://%s%s", s.addr, s.basePath)
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := s.httpClient.Do(req)
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 	if err != nil {
 		return Stats{Model: model}, NewConnectionError("failed to get stats", err)
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return Stats{Model: model}, NewDeviceError("failed to parse stats", "invalid JSON response", err)
 	}
-	
+
 	stats := Stats{Model: model}
-	
+
 	// Extract hashrate information
 	if hashrate, ok := result["hashrate_instant"].(float64); ok {
-		stats.Hashrate5s = hashrate / 1000000000 // Convert to GH/s
+		stats.Hashrate5s = HashrateValue(hashrate) // result is already in H/s
 	} else if hashrate, ok := result["hashrate"].(string); ok {
-		// Sometimes hashrate comes as string like "95.12 TH/s"
-		if parsed := parseHashrateString(hashrate); parsed > 0 {
+		// Sometimes hashrate comes as a unit string like "95.12 TH/s"
+		if parsed, err := ParseHashrateString(hashrate); err == nil {
 			stats.Hashrate5s = parsed
 		}
 	}
-	
+
 	if hashrateAvg, ok := result["hashrate_avg"].(float64); ok {
-		stats.HashrateAv = hashrateAvg / 1000000000
+		stats.HashrateAv = HashrateValue(hashrateAvg)
 	} else {
 		stats.HashrateAv = stats.Hashrate5s
 	}
-	
+
 	// Extract temperature
 	if temp, ok := result["temp_max"].(float64); ok {
 		stats.TempMax = temp
@@ -143,107 +217,293 @@ func (s *whatsminerSession) Stats(ctx context.Context) (Stats, error) {
 			stats.TempMax = maxTemp
 		}
 	}
-	
+
 	// Extract uptime
 	if uptime, ok := result["uptime"].(float64); ok {
 		stats.UptimeSec = int64(uptime)
 	}
-	
+
+	return stats, nil
+}
+
+// statsTCP fills Stats from the mm API's "summary" command (cgminer-style
+// SUMMARY response: "GHS 5s"/"GHS av"/"Elapsed"), then also reads "devs"
+// and, when that call succeeds, raises TempMax to the hottest board's
+// temperature.
+func (s *whatsminerSession) statsTCP(ctx context.Context, model Model) (Stats, error) {
+	resp, err := mmReadCmd(ctx, s.addr, "summary")
+	if err != nil {
+		return Stats{Model: model}, err
+	}
+
+	stats := Stats{Model: model}
+	for _, entry := range mmEntries(resp, "SUMMARY") {
+		if v, ok := mmFloat(entry, "GHS 5s"); ok {
+			stats.Hashrate5s = HashrateFromGHS(v)
+		}
+		if v, ok := mmFloat(entry, "GHS av"); ok {
+			stats.HashrateAv = HashrateFromGHS(v)
+		}
+		if v, ok := mmFloat(entry, "Temperature"); ok {
+			stats.TempMax = v
+		}
+		if v, ok := mmFloat(entry, "Elapsed"); ok {
+			stats.UptimeSec = int64(v)
+		}
+	}
+
+	if devs, err := mmReadCmd(ctx, s.addr, "devs"); err == nil {
+		for _, entry := range mmEntries(devs, "DEVS") {
+			if v, ok := mmFloat(entry, "Temperature"); ok && v > stats.TempMax {
+				stats.TempMax = v
+			}
+		}
+	}
+
 	return stats, nil
 }
 
 func (s *whatsminerSession) Summary(ctx context.Context) (Summary, error) {
-	if !s.useHTTP {
-		return Summary{}, NewDeviceError("summary not available", "TCP mode not implemented", nil)
+	if s.useHTTP {
+		if summary, err := s.summaryHTTP(ctx); err == nil {
+			return summary, nil
+		}
+		s.fellBackToTCP()
 	}
-	
+	return s.summaryTCP(ctx)
+}
+
+func (s *whatsminerSession) summaryHTTP(ctx context.Context) (Summary, error) {
 	s.ensureClient()
-	
+
 	url := fmt.Sprintf("http://%s%s", s.addr, s.basePath)
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := s.httpClient.Do(req)
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 	if err != nil {
 		return Summary{}, NewConnectionError("failed to get summary", err)
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return Summary{}, NewDeviceError("failed to parse summary", "invalid JSON response", err)
 	}
-	
+
 	summary := Summary{}
-	
+
 	if accepted, ok := result["accepted"].(float64); ok {
 		summary.Accepted = int64(accepted)
 	}
-	
+
 	if rejected, ok := result["rejected"].(float64); ok {
 		summary.Rejected = int64(rejected)
 	}
-	
+
 	if hashrate, ok := result["hashrate_instant"].(float64); ok {
-		ghash := hashrate / 1000000000 // Convert to GH/s
-		summary.GHS5s = ghash
-		summary.GHSav = ghash
+		hr := HashrateValue(hashrate) // result is already in H/s
+		summary.GHS5s = hr
+		summary.GHSav = hr
 	}
-	
+
 	if hashrateAvg, ok := result["hashrate_avg"].(float64); ok {
-		summary.GHSav = hashrateAvg / 1000000000
+		summary.GHSav = HashrateValue(hashrateAvg)
+	}
+
+	return summary, nil
+}
+
+// summaryTCP fills Summary from the mm API's "summary" command.
+func (s *whatsminerSession) summaryTCP(ctx context.Context) (Summary, error) {
+	resp, err := mmReadCmd(ctx, s.addr, "summary")
+	if err != nil {
+		return Summary{}, err
+	}
+
+	summary := Summary{}
+	for _, entry := range mmEntries(resp, "SUMMARY") {
+		if v, ok := mmFloat(entry, "Accepted"); ok {
+			summary.Accepted = int64(v)
+		}
+		if v, ok := mmFloat(entry, "Rejected"); ok {
+			summary.Rejected = int64(v)
+		}
+		if v, ok := mmFloat(entry, "Device Hardware%"); ok {
+			summary.DeviceHardwarePercent = v
+		}
+		if v, ok := mmFloat(entry, "GHS 5s"); ok {
+			summary.GHS5s = HashrateFromGHS(v)
+		}
+		if v, ok := mmFloat(entry, "GHS av"); ok {
+			summary.GHSav = HashrateFromGHS(v)
+		}
 	}
-	
 	return summary, nil
 }
 
+// Pools has no working HTTP equivalent on this firmware family; it always
+// goes through the mm API's "pools" command.
 func (s *whatsminerSession) Pools(ctx context.Context) ([]Pool, error) {
-	return nil, NewDeviceError("pool management not implemented", "Whatsminer pool management via HTTP not yet implemented", nil)
+	resp, err := mmReadCmd(ctx, s.addr, "pools")
+	if err != nil {
+		return nil, err
+	}
+
+	var pools []Pool
+	for _, entry := range mmEntries(resp, "POOLS") {
+		id, _ := mmFloat(entry, "POOL")
+		url, _ := entry["URL"].(string)
+		user, _ := entry["User"].(string)
+		prio, _ := mmFloat(entry, "Priority")
+		active, _ := entry["Stratum Active"].(bool)
+		pools = append(pools, Pool{
+			ID:       int64(id),
+			URL:      url,
+			User:     user,
+			Priority: int64(prio),
+			Active:   active,
+		})
+	}
+	return pools, nil
 }
 
+// AddPool appends url/user/pass to the device's pool list, preferring the
+// HTTP set.cgi form endpoint and falling back to the mm API's atomic
+// "update_pools" command, which replaces the whole list in one call, so the
+// existing pools are read back first and resubmitted alongside the new one.
 func (s *whatsminerSession) AddPool(ctx context.Context, url, user, pass string) error {
-	return NewDeviceError("add pool not implemented", "Whatsminer pool management not yet implemented", nil)
+	if s.useHTTP {
+		if err := s.poolActionHTTP(ctx, neturl.Values{"action": {"addpool"}, "pool_url": {url}, "pool_user": {user}, "pool_pass": {pass}}); err == nil {
+			return nil
+		}
+		s.fellBackToTCP()
+	}
+	return s.modifyPoolsTCP(ctx, poolOpAdd, Pool{URL: url, User: user}, pass)
 }
 
 func (s *whatsminerSession) EnablePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("enable pool not implemented", "Whatsminer pool management not yet implemented", nil)
+	if s.useHTTP {
+		if err := s.poolActionHTTP(ctx, neturl.Values{"action": {"enablepool"}, "pool_id": {strconv.FormatInt(poolID, 10)}}); err == nil {
+			return nil
+		}
+		s.fellBackToTCP()
+	}
+	return s.modifyPoolsTCP(ctx, poolOpEnable, Pool{ID: poolID}, "")
 }
 
 func (s *whatsminerSession) DisablePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("disable pool not implemented", "Whatsminer pool management not yet implemented", nil)
+	if s.useHTTP {
+		if err := s.poolActionHTTP(ctx, neturl.Values{"action": {"disablepool"}, "pool_id": {strconv.FormatInt(poolID, 10)}}); err == nil {
+			return nil
+		}
+		s.fellBackToTCP()
+	}
+	return s.modifyPoolsTCP(ctx, poolOpDisable, Pool{ID: poolID}, "")
 }
 
 func (s *whatsminerSession) RemovePool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("remove pool not implemented", "Whatsminer pool management not yet implemented", nil)
+	if s.useHTTP {
+		if err := s.poolActionHTTP(ctx, neturl.Values{"action": {"removepool"}, "pool_id": {strconv.FormatInt(poolID, 10)}}); err == nil {
+			return nil
+		}
+		s.fellBackToTCP()
+	}
+	return s.modifyPoolsTCP(ctx, poolOpRemove, Pool{ID: poolID}, "")
 }
 
+// poolActionHTTP submits a pool mutation to the firmware's /cgi-bin/set.cgi
+// form endpoint, which not every Whatsminer firmware exposes; failure here
+// is expected and just sends the caller back to the TCP mm API.
+func (s *whatsminerSession) poolActionHTTP(ctx context.Context, values neturl.Values) error {
+	s.ensureClient()
+
+	reqURL := fmt.Sprintf("http://%s/cgi-bin/set.cgi", s.addr)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, strings.NewReader(values.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
+	if err != nil {
+		return NewConnectionError("pool action failed", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return NewDeviceError("pool action failed", fmt.Sprintf("set.cgi returned %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// modifyPoolsTCP reads the device's current pool list, applies op via
+// mergePoolChange, and resubmits the whole list through the mm API's
+// "update_pools" command. newPass is only honored for poolOpAdd: mm's POOLS
+// response never carries a pool's password, so every existing entry is
+// resubmitted with an empty one (matching how the firmware already treats
+// a blank password on update_pools as "leave it as configured").
+func (s *whatsminerSession) modifyPoolsTCP(ctx context.Context, op poolOp, target Pool, newPass string) error {
+	existing, err := s.Pools(ctx)
+	if err != nil {
+		return err
+	}
+	merged := mergePoolChange(existing, op, target)
+
+	entries := make([]string, 0, len(merged))
+	for _, p := range merged {
+		pass := ""
+		if op == poolOpAdd && p.URL == target.URL && p.User == target.User {
+			pass = newPass
+		}
+		entries = append(entries, fmt.Sprintf("%s,%s,%s", p.URL, p.User, pass))
+	}
+
+	_, err = mmWrite(ctx, s.addr, s.password, "update_pools", strings.Join(entries, "|"))
+	return err
+}
+
+// SwitchPool activates poolID via the mm API's "switchpool" command.
 func (s *whatsminerSession) SwitchPool(ctx context.Context, poolID int64) error {
-	return NewDeviceError("switch pool not implemented", "Whatsminer pool management not yet implemented", nil)
+	_, err := mmWrite(ctx, s.addr, s.password, "switchpool", strconv.FormatInt(poolID, 10))
+	return err
+}
+
+func (s *whatsminerSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return NewDeviceError("update pool not implemented", "Whatsminer's full-list pool replace has no conflict detection to update safely", ErrNotImplemented)
 }
 
 func (s *whatsminerSession) Restart(ctx context.Context) error {
-	if !s.useHTTP {
-		return NewDeviceError("restart not available", "TCP mode not implemented", nil)
+	if s.useHTTP {
+		if err := s.restartHTTP(ctx); err == nil {
+			return nil
+		}
+		s.fellBackToTCP()
 	}
-	
+	return s.restartTCP(ctx)
+}
+
+func (s *whatsminerSession) restartHTTP(ctx context.Context) error {
 	s.ensureClient()
-	
+
 	// Try common restart endpoints
 	endpoints := []string{"/cgi-bin/restart.cgi", "/api/restart"}
-	
+
 	for _, endpoint := range endpoints {
 		url := fmt.Sprintf("http://%s%s", s.addr, endpoint)
 		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
-		resp, err := s.httpClient.Do(req)
+		resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 		if err != nil {
 			continue
 		}
 		resp.Body.Close()
-		
+
 		if resp.StatusCode < 400 {
 			return nil // Success
 		}
 	}
-	
-	return NewDeviceError("restart failed", "no working restart endpoint found", nil)
+
+	return NewConnectionError("restart failed", fmt.Errorf("no working restart endpoint found"))
+}
+
+// restartTCP reboots the device via the mm API's "reboot" write command.
+func (s *whatsminerSession) restartTCP(ctx context.Context) error {
+	_, err := mmWrite(ctx, s.addr, s.password, "reboot", nil)
+	return err
 }
 
 func (s *whatsminerSession) Quit(ctx context.Context) error {
@@ -258,25 +518,25 @@ func (s *whatsminerSession) GetPowerMode(ctx context.Context) (PowerMode, error)
 	if !s.useHTTP {
 		return PowerMode{Kind: PowerBalanced}, NewDeviceError("power mode not available", "TCP mode not implemented", nil)
 	}
-	
+
 	s.ensureClient()
-	
+
 	// Try to get power mode from status
 	url := fmt.Sprintf("http://%s%s", s.addr, s.basePath)
 	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	resp, err := s.httpClient.Do(req)
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
 	if err != nil {
 		return PowerMode{Kind: PowerBalanced}, nil // Default fallback
 	}
 	defer resp.Body.Close()
-	
+
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return PowerMode{Kind: PowerBalanced}, nil
 	}
-	
+
 	mode := PowerMode{Kind: PowerBalanced}
-	
+
 	// Look for power mode indicators
 	if powerMode, ok := result["power_mode"].(string); ok {
 		switch strings.ToLower(powerMode) {
@@ -288,49 +548,302 @@ func (s *whatsminerSession) GetPowerMode(ctx context.Context) (PowerMode, error)
 			mode.Kind = PowerCustom
 		}
 	}
-	
+
 	if power, ok := result["power_consumption"].(float64); ok {
 		mode.Watts = int(power)
 	}
-	
+
 	return mode, nil
 }
 
+// SetPowerMode has no HTTP equivalent on this firmware family; it always
+// goes through the mm API, using "set_target_freq" when a specific
+// frequency was requested and "set_miner_fast" otherwise.
 func (s *whatsminerSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
-	return NewDeviceError("power mode setting not implemented", "Whatsminer power mode control not yet implemented", nil)
+	if mode.FreqMHz > 0 {
+		_, err := mmWrite(ctx, s.addr, s.password, "set_target_freq", strconv.Itoa(mode.FreqMHz))
+		return err
+	}
+
+	var param string
+	switch mode.Kind {
+	case PowerLow:
+		param = "low"
+	case PowerHigh:
+		param = "high"
+	default:
+		param = "normal"
+	}
+	_, err := mmWrite(ctx, s.addr, s.password, "set_miner_fast", param)
+	return err
 }
 
 func (s *whatsminerSession) GetFan(ctx context.Context) (FanConfig, error) {
-	return FanConfig{Mode: FanAuto}, NewDeviceError("fan control not implemented", "Whatsminer fan reading not yet implemented", nil)
+	if s.useHTTP {
+		if fan, err := s.getFanHTTP(ctx); err == nil {
+			return fan, nil
+		}
+		s.fellBackToTCP()
+	}
+	return s.getFanTCP(ctx)
 }
 
+func (s *whatsminerSession) getFanHTTP(ctx context.Context) (FanConfig, error) {
+	s.ensureClient()
+
+	url := fmt.Sprintf("http://%s%s", s.addr, s.basePath)
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	resp, err := doHTTP(ctx, s.httpClient, req, s.opt)
+	if err != nil {
+		return FanConfig{}, NewConnectionError("failed to get fan status", err)
+	}
+	defer resp.Body.Close()
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return FanConfig{}, NewDeviceError("failed to parse fan status", "invalid JSON response", err)
+	}
+
+	fan := FanConfig{Mode: FanAuto}
+	if mode, ok := result["fan_mode"].(string); ok && strings.EqualFold(mode, "manual") {
+		fan.Mode = FanManual
+	}
+	if speed, ok := result["fan_speed"].(float64); ok {
+		fan.SpeedPct = int(speed)
+	} else if speed, ok := result["fan_speed_pct"].(float64); ok {
+		fan.SpeedPct = int(speed)
+	}
+	return fan, nil
+}
+
+// getFanTCP reads fan speed from the mm API's "devs" command. Fan mode
+// can't be determined this way (the protocol only reports a percent, not
+// an auto/manual flag), so it's always reported as auto.
+func (s *whatsminerSession) getFanTCP(ctx context.Context) (FanConfig, error) {
+	resp, err := mmReadCmd(ctx, s.addr, "devs")
+	if err != nil {
+		return FanConfig{}, err
+	}
+
+	fan := FanConfig{Mode: FanAuto}
+	for _, entry := range mmEntries(resp, "DEVS") {
+		if v, ok := mmFloat(entry, "Fan Speed Percent"); ok {
+			fan.SpeedPct = int(v)
+			break
+		}
+	}
+	return fan, nil
+}
+
+// SetFan has no HTTP equivalent on this firmware family; it always goes
+// through the mm API's "set_fan_speed" write command.
 func (s *whatsminerSession) SetFan(ctx context.Context, fan FanConfig) error {
-	return NewDeviceError("fan control not implemented", "Whatsminer fan control not yet implemented", nil)
-}
-
-// parseHashrateString parses hashrate strings like "95.12 TH/s" to GH/s
-func parseHashrateString(hashrate string) float64 {
-	// Use regex to extract number and unit
-	re := regexp.MustCompile(`([\d\.]+)\s*([KMGT]?)H/s`)
-	matches := re.FindStringSubmatch(hashrate)
-	if len(matches) < 3 {
-		return 0
-	}
-	
-	var value float64
-	fmt.Sscanf(matches[1], "%f", &value)
-	
-	// Convert to GH/s based on unit
-	switch matches[2] {
-	case "T":
-		return value * 1000 // TH/s to GH/s
-	case "K":
-		return value / 1000 // KH/s to GH/s
-	case "M":
-		return value // MH/s to GH/s (approximately)
-	case "", "G":
-		return value // GH/s
-	default:
-		return value
+	if fan.Mode == FanAuto {
+		_, err := mmWrite(ctx, s.addr, s.password, "set_fan_speed", "auto")
+		return err
+	}
+	_, err := mmWrite(ctx, s.addr, s.password, "set_fan_speed", strconv.Itoa(fan.SpeedPct))
+	return err
+}
+
+// mmAPIPort is the default TCP port for Whatsminer's cgminer-derived "mm
+// API" (WhatsminerTool protocol): newline-terminated JSON over raw TCP.
+const mmAPIPort = "4028"
+
+// mmReadCmd sends a read-only command (no auth required) to address's mm
+// API, e.g. "summary", "pools", "devs", "status", "version".
+func mmReadCmd(ctx context.Context, address, cmd string) (map[string]interface{}, error) {
+	return mmCall(ctx, address, map[string]string{"cmd": cmd})
+}
+
+// mmCall sends req to address as a newline-terminated JSON request and
+// decodes the newline-terminated JSON response, defaulting address's port
+// to mmAPIPort if it doesn't carry one.
+func mmCall(ctx context.Context, address string, req interface{}) (map[string]interface{}, error) {
+	host := address
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		host = net.JoinHostPort(address, mmAPIPort)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, NewConnectionError("failed to dial whatsminer mm API", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(3 * time.Second)
+	if dl, ok := ctx.Deadline(); ok {
+		deadline = dl
+	}
+	conn.SetDeadline(deadline)
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, NewDeviceError("failed to encode mm API request", "", err)
 	}
+	if _, err := conn.Write(append(body, '\n')); err != nil {
+		return nil, NewConnectionError("failed to write mm API request", err)
+	}
+
+	line, err := bufio.NewReader(conn).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, NewConnectionError("failed to read mm API response", err)
+	}
+	line = bytes.TrimRight(line, "\x00\n\r")
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(line, &result); err != nil {
+		return nil, NewDeviceError("failed to parse mm API response", "invalid JSON", err)
+	}
+	if err := mmStatusError(result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mmStatusError inspects a decoded mm API response's STATUS array and
+// returns an error if the device reported failure, classifying a rejected
+// token (bad admin password) separately from any other device error so
+// callers can tell "device rejected token" from "device unreachable".
+func mmStatusError(result map[string]interface{}) error {
+	status, ok := result["STATUS"].([]interface{})
+	if !ok || len(status) == 0 {
+		return nil
+	}
+	entry, ok := status[0].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if code, _ := entry["STATUS"].(string); code != "E" {
+		return nil
+	}
+
+	msg, _ := entry["Msg"].(string)
+	if strings.Contains(strings.ToLower(msg), "token") {
+		return NewUnauthorizedError("whatsminer rejected token: " + msg)
+	}
+	return NewDeviceError("whatsminer mm API error", msg, nil)
+}
+
+// mmEntries extracts the named array of objects from a decoded mm API
+// response, e.g. mmEntries(resp, "SUMMARY") for a "summary" command's
+// result.
+func mmEntries(resp map[string]interface{}, key string) []map[string]interface{} {
+	raw, ok := resp[key].([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]map[string]interface{}, 0, len(raw))
+	for _, e := range raw {
+		if m, ok := e.(map[string]interface{}); ok {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func mmFloat(entry map[string]interface{}, key string) (float64, bool) {
+	v, ok := entry[key].(float64)
+	return v, ok
+}
+
+// mmToken is the one-time signature needed to authorize a single mm API
+// write command; tokens aren't reusable across commands.
+type mmToken struct {
+	Token string
+	Time  string
+}
+
+// mmGetToken requests a fresh salt/time pair from address's "get_token"
+// command and signs it with password using the scheme Whatsminer firmware
+// expects: sha256(password) -> md5(that + salt) -> sha256(that + time).
+func mmGetToken(ctx context.Context, address, password string) (mmToken, error) {
+	resp, err := mmCall(ctx, address, map[string]string{"cmd": "get_token"})
+	if err != nil {
+		return mmToken{}, err
+	}
+
+	msg, ok := resp["Msg"].(map[string]interface{})
+	if !ok {
+		return mmToken{}, NewDeviceError("get_token failed", "missing Msg in response", nil)
+	}
+	salt, _ := msg["salt"].(string)
+	ts, _ := msg["time"].(string)
+	if salt == "" || ts == "" {
+		return mmToken{}, NewDeviceError("get_token failed", "missing salt/time in response", nil)
+	}
+
+	pwdHash := sha256Hex(password)
+	signed := md5Hex(pwdHash + salt)
+	token := sha256Hex(signed + ts)
+
+	return mmToken{Token: token, Time: ts}, nil
+}
+
+// mmWrite performs an authenticated mm API write command: it fetches a
+// fresh token, AES-encrypts param into the "enc" envelope the firmware
+// requires, and sends {cmd, token, enc, param}. password must be the
+// device's admin password (Endpoint.Credentials["admin_password"]).
+func mmWrite(ctx context.Context, address, password, cmd string, param interface{}) (map[string]interface{}, error) {
+	if password == "" {
+		return nil, NewUnauthorizedError("whatsminer admin password required for write commands")
+	}
+
+	tok, err := mmGetToken(ctx, address, password)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, err := mmEncryptParam(password, param)
+	if err != nil {
+		return nil, NewDeviceError("failed to build mm API request", "param encryption failed", err)
+	}
+
+	payload := map[string]interface{}{
+		"cmd":   cmd,
+		"token": tok.Token,
+		"enc":   1,
+		"param": enc,
+	}
+	return mmCall(ctx, address, payload)
+}
+
+// mmEncryptParam encrypts param (JSON-marshaled, then PKCS#7 padded) under
+// AES-256-ECB keyed by sha256(password), the "enc" envelope Whatsminer
+// firmware requires for any mutating command.
+func mmEncryptParam(password string, param interface{}) (string, error) {
+	plain, err := json.Marshal(param)
+	if err != nil {
+		return "", err
+	}
+
+	key := sha256.Sum256([]byte(password))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return "", err
+	}
+
+	padded := pkcs7Pad(plain, aes.BlockSize)
+	out := make([]byte, len(padded))
+	for i := 0; i < len(padded); i += aes.BlockSize {
+		block.Encrypt(out[i:i+aes.BlockSize], padded[i:i+aes.BlockSize])
+	}
+
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+func pkcs7Pad(data []byte, blockSize int) []byte {
+	padLen := blockSize - len(data)%blockSize
+	return append(data, bytes.Repeat([]byte{byte(padLen)}, padLen)...)
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,39 @@
+package multiminer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWhatsminerSessionAddPoolHTTP(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/cgi-bin/set.cgi" {
+			t.Errorf("unexpected path %q", r.URL.Path)
+		}
+		if r.FormValue("action") != "addpool" {
+			t.Errorf("expected action=addpool, got %q", r.FormValue("action"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	sess := &whatsminerSession{addr: srv.Listener.Addr().String(), useHTTP: true}
+	if err := sess.AddPool(context.Background(), "stratum+tcp://pool:3333", "worker.1", "x"); err != nil {
+		t.Fatalf("AddPool: %v", err)
+	}
+}
+
+func TestWhatsminerSessionPoolActionFallsBackToTCP(t *testing.T) {
+	// No listener at all on the HTTP path: poolActionHTTP should fail and
+	// the caller should fall through to the (also failing, since there's
+	// no mm API here) TCP path rather than panicking or hanging.
+	sess := &whatsminerSession{addr: "127.0.0.1:0", useHTTP: true}
+	if err := sess.EnablePool(context.Background(), 1); err == nil {
+		t.Fatal("expected an error with neither HTTP nor TCP reachable")
+	}
+	if sess.useHTTP {
+		t.Error("expected session to have fallen back to TCP after HTTP failure")
+	}
+}
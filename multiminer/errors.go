@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 )
 
 // Error codes for structured error handling
@@ -14,17 +15,39 @@ const (
 	ErrCodeConnectionFailed = "CONNECTION_FAILED"
 	ErrCodeTimeout          = "TIMEOUT"
 	ErrCodeUnauthorized     = "UNAUTHORIZED"
+	ErrCodeForbidden        = "FORBIDDEN"
 	ErrCodeDriverNotFound   = "DRIVER_NOT_FOUND"
 	ErrCodeDeviceError      = "DEVICE_ERROR"
 	ErrCodeInternalError    = "INTERNAL_ERROR"
+	ErrCodeConflict         = "CONFLICT"
+)
+
+// ErrClassification tells a retrying caller (in particular the connection
+// pool's circuit breaker) how an error should influence future attempts.
+type ErrClassification string
+
+const (
+	// ClassificationTransient marks a failure that's likely to clear up on
+	// its own (a dropped connection, a momentary timeout). Several of these
+	// in a row trip the breaker open with backoff.
+	ClassificationTransient ErrClassification = "Transient"
+	// ClassificationPermanent marks a failure the driver isn't going to
+	// recover from by itself (unimplemented command, auth refused). A
+	// single one trips the breaker immediately until reset.
+	ClassificationPermanent ErrClassification = "Permanent"
+	// ClassificationRetryable marks a failure that's safe to retry right
+	// away and shouldn't count against the breaker at all (e.g. the pool
+	// was momentarily at its open-connection limit).
+	ClassificationRetryable ErrClassification = "Retryable"
 )
 
 // MultiMinerError provides structured error information
 type MultiMinerError struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details string `json:"details,omitempty"`
-	Cause   error  `json:"-"`
+	Code           string            `json:"code"`
+	Message        string            `json:"message"`
+	Details        string            `json:"details,omitempty"`
+	Classification ErrClassification `json:"classification,omitempty"`
+	Cause          error             `json:"-"`
 }
 
 func (e *MultiMinerError) Error() string {
@@ -49,8 +72,12 @@ func (e *MultiMinerError) HTTPStatus() int {
 		return http.StatusBadRequest
 	case ErrCodeUnauthorized:
 		return http.StatusUnauthorized
+	case ErrCodeForbidden:
+		return http.StatusForbidden
 	case ErrCodeConnectionFailed, ErrCodeTimeout, ErrCodeDeviceError:
 		return http.StatusBadGateway
+	case ErrCodeConflict:
+		return http.StatusConflict
 	default:
 		return http.StatusInternalServerError
 	}
@@ -60,6 +87,11 @@ func (e *MultiMinerError) HTTPStatus() int {
 var (
 	ErrNotFound       = &MultiMinerError{Code: ErrCodeNotFound, Message: "device not found"}
 	ErrNotImplemented = &MultiMinerError{Code: ErrCodeNotImplemented, Message: "not implemented"}
+
+	// ErrConflict is returned by Session.UpdatePool once its
+	// read-modify-write loop has exhausted its retry budget because every
+	// attempt raced a concurrent change to the same pool.
+	ErrConflict = &MultiMinerError{Code: ErrCodeConflict, Message: "pool changed concurrently, giving up after retries"}
 )
 
 // Error constructors
@@ -88,6 +120,14 @@ func NewTimeoutError(details string) *MultiMinerError {
 	}
 }
 
+func NewUnauthorizedError(message string) *MultiMinerError {
+	return &MultiMinerError{Code: ErrCodeUnauthorized, Message: message}
+}
+
+func NewForbiddenError(message string) *MultiMinerError {
+	return &MultiMinerError{Code: ErrCodeForbidden, Message: message}
+}
+
 func NewDriverNotFoundError() *MultiMinerError {
 	return &MultiMinerError{Code: ErrCodeDriverNotFound, Message: "no suitable driver found"}
 }
@@ -101,6 +141,57 @@ func NewDeviceError(message, details string, cause error) *MultiMinerError {
 	}
 }
 
+// NewPermanentError builds a device error classified as Permanent: the
+// driver fundamentally can't talk to the box (not implemented, auth
+// refused), so the connection pool's circuit breaker trips immediately
+// rather than retrying.
+func NewPermanentError(message, details string, cause error) *MultiMinerError {
+	return &MultiMinerError{
+		Code:           ErrCodeDeviceError,
+		Message:        message,
+		Details:        details,
+		Classification: ClassificationPermanent,
+		Cause:          cause,
+	}
+}
+
+// NewTransientError builds a device error classified as Transient: likely
+// to clear up on its own, but counted against the circuit breaker so
+// repeated occurrences still trip it open with backoff.
+func NewTransientError(message, details string, cause error) *MultiMinerError {
+	return &MultiMinerError{
+		Code:           ErrCodeDeviceError,
+		Message:        message,
+		Details:        details,
+		Classification: ClassificationTransient,
+		Cause:          cause,
+	}
+}
+
+// NewRetryableError builds an error classified as Retryable: safe to retry
+// immediately and excluded from circuit breaker accounting, e.g. the pool
+// was momentarily at its open-connection limit.
+func NewRetryableError(message, details string, cause error) *MultiMinerError {
+	return &MultiMinerError{
+		Code:           ErrCodeDeviceError,
+		Message:        message,
+		Details:        details,
+		Classification: ClassificationRetryable,
+		Cause:          cause,
+	}
+}
+
+// UnsupportedTuningError reports which requested PowerMode fields a driver's
+// capabilities don't support, so callers can surface a precise message
+// instead of a generic failure.
+type UnsupportedTuningError struct {
+	Fields []string
+}
+
+func (e *UnsupportedTuningError) Error() string {
+	return fmt.Sprintf("unsupported power tuning fields: %s", strings.Join(e.Fields, ", "))
+}
+
 // IsMultiMinerError checks if an error is a MultiMinerError
 func IsMultiMinerError(err error) (*MultiMinerError, bool) {
 	var mErr *MultiMinerError
@@ -110,6 +201,14 @@ func IsMultiMinerError(err error) (*MultiMinerError, bool) {
 	return nil, false
 }
 
+// IsPermanent reports whether err is a MultiMinerError classified as
+// Permanent, i.e. one the circuit breaker should trip on immediately
+// instead of counting towards the transient-failure threshold.
+func IsPermanent(err error) bool {
+	mErr, ok := IsMultiMinerError(err)
+	return ok && mErr.Classification == ClassificationPermanent
+}
+
 // WrapError wraps a generic error with MultiMinerError
 func WrapError(err error, code, message string) *MultiMinerError {
 	return &MultiMinerError{
@@ -117,4 +216,4 @@ func WrapError(err error, code, message string) *MultiMinerError {
 		Message: message,
 		Cause:   err,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,441 @@
+package multiminer
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// EventType names a kind of alerting event a Monitor can emit.
+type EventType string
+
+const (
+	EventHashrateDrop          EventType = "HashrateDrop"
+	EventTempThresholdExceeded EventType = "TempThresholdExceeded"
+	EventPoolDisconnected      EventType = "PoolDisconnected"
+	EventMinerUnreachable      EventType = "MinerUnreachable"
+	EventPowerModeChanged      EventType = "PowerModeChanged"
+	EventFanFault              EventType = "FanFault"
+)
+
+// Event is a single alerting occurrence, self-contained enough for a
+// downstream system to route and display without re-querying the device.
+type Event struct {
+	Type     EventType          `json:"type"`
+	Time     time.Time          `json:"time"`
+	MinerID  MinerID            `json:"miner_id"`
+	Driver   string             `json:"driver"`
+	Endpoint string             `json:"endpoint"`
+	Model    Model              `json:"model"`
+	Message  string             `json:"message"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"`
+}
+
+// EventSink receives every Event an EventBus publishes.
+type EventSink interface {
+	Publish(ctx context.Context, ev Event)
+}
+
+// eventSubBuffer bounds how far a channel subscriber can lag before new
+// events are dropped for it, same drop-on-overflow policy as condSubBuffer.
+const eventSubBuffer = 64
+
+// EventBus fans Events out to registered sinks and Go channel subscribers.
+type EventBus struct {
+	mu    sync.RWMutex
+	sinks []EventSink
+
+	subMu sync.RWMutex
+	subs  map[chan Event]struct{}
+}
+
+// NewEventBus creates an empty EventBus.
+func NewEventBus() *EventBus {
+	return &EventBus{subs: make(map[chan Event]struct{})}
+}
+
+// AddSink registers s to receive every future published Event.
+func (b *EventBus) AddSink(s EventSink) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, s)
+}
+
+// Subscribe returns a channel of every published Event and an unsubscribe
+// func; the channel is closed once unsubscribe is called.
+func (b *EventBus) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, eventSubBuffer)
+
+	b.subMu.Lock()
+	b.subs[ch] = struct{}{}
+	b.subMu.Unlock()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			b.subMu.Lock()
+			delete(b.subs, ch)
+			b.subMu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// Publish fans ev out to every sink and every channel subscriber, dropping
+// (and logging) for subscribers whose channel is full.
+func (b *EventBus) Publish(ctx context.Context, ev Event) {
+	b.mu.RLock()
+	sinks := append([]EventSink(nil), b.sinks...)
+	b.mu.RUnlock()
+	for _, s := range sinks {
+		s.Publish(ctx, ev)
+	}
+
+	b.subMu.RLock()
+	defer b.subMu.RUnlock()
+	for ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+			LogWarn(ctx, "dropping event for slow subscriber",
+				F("type", string(ev.Type)), F("id", string(ev.MinerID)))
+		}
+	}
+}
+
+// StdoutSink writes each Event as a JSON line to w (os.Stdout by default).
+type StdoutSink struct {
+	w io.Writer
+}
+
+// NewStdoutSink creates a StdoutSink writing to os.Stdout.
+func NewStdoutSink() *StdoutSink { return &StdoutSink{w: os.Stdout} }
+
+func (s *StdoutSink) Publish(ctx context.Context, ev Event) {
+	enc := json.NewEncoder(s.w)
+	_ = enc.Encode(ev)
+}
+
+// WebhookConfig configures a WebhookSink.
+type WebhookConfig struct {
+	URL string
+	// Secret, if set, HMAC-SHA256 signs the JSON body into the
+	// X-Signature-256 header as "sha256=<hex>", same convention as GitHub
+	// webhooks, so receivers can verify authenticity.
+	Secret string
+
+	Timeout          time.Duration
+	MaxRetries       int
+	RetryBaseBackoff time.Duration
+	RetryMaxBackoff  time.Duration
+}
+
+// WebhookSink POSTs each Event as JSON to cfg.URL, retrying with
+// exponential backoff on failure.
+type WebhookSink struct {
+	cfg    WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookSink creates a WebhookSink posting to cfg.URL.
+func NewWebhookSink(cfg WebhookConfig) *WebhookSink {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.RetryBaseBackoff <= 0 {
+		cfg.RetryBaseBackoff = time.Second
+	}
+	if cfg.RetryMaxBackoff <= 0 {
+		cfg.RetryMaxBackoff = 30 * time.Second
+	}
+	return &WebhookSink{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, ev Event) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		LogWarn(ctx, "failed to marshal event for webhook", F("error", err))
+		return
+	}
+
+	backoff := s.cfg.RetryBaseBackoff
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if err := s.post(ctx, body); err == nil {
+			return
+		} else if attempt == s.cfg.MaxRetries {
+			LogWarn(ctx, "webhook sink giving up after retries",
+				F("url", s.cfg.URL), F("type", string(ev.Type)), F("error", err))
+			return
+		}
+		time.Sleep(backoff)
+		backoff = nextBackoff(backoff, s.cfg.RetryMaxBackoff)
+	}
+}
+
+func (s *WebhookSink) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.cfg.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return NewDeviceError("webhook rejected event", resp.Status, nil)
+	}
+	return nil
+}
+
+// MonitorThresholds configures per-device alert thresholds for a Monitor. A
+// zero field disables that particular check.
+type MonitorThresholds struct {
+	// HashrateDropRatio triggers HashrateDrop once Hashrate5s stays below
+	// HashrateDropRatio*HashrateAv for at least HashrateDropFor.
+	HashrateDropRatio float64
+	HashrateDropFor   time.Duration
+
+	// MaxTempC triggers TempThresholdExceeded once TempMax stays above
+	// MaxTempC for at least MaxTempFor.
+	MaxTempC   float64
+	MaxTempFor time.Duration
+}
+
+// monitorState tracks the per-device history a Monitor needs to turn
+// instantaneous readings into "for N minutes" threshold breaches.
+type monitorState struct {
+	hashrateLowSince time.Time
+	tempHighSince    time.Time
+	lastPool         string
+	lastPowerMode    PowerModeKind
+	havePowerMode    bool
+	unreachable      bool
+}
+
+// Monitor periodically polls a Manager's devices via Stats, Pools,
+// GetPowerMode and GetFan and publishes alerting Events to an EventBus when per-device thresholds
+// are breached, so alerting rules live with the monitor instead of being
+// re-derived by every consumer of raw metrics.
+type Monitor struct {
+	mgr *Manager
+	bus *EventBus
+
+	mu                sync.Mutex
+	defaultThresholds MonitorThresholds
+	thresholds        map[MinerID]MonitorThresholds
+	state             map[MinerID]*monitorState
+}
+
+// NewMonitor creates a Monitor publishing to bus, using defaults for any
+// device without a more specific SetThresholds override.
+func NewMonitor(mgr *Manager, bus *EventBus, defaults MonitorThresholds) *Monitor {
+	return &Monitor{
+		mgr:               mgr,
+		bus:               bus,
+		defaultThresholds: defaults,
+		thresholds:        make(map[MinerID]MonitorThresholds),
+		state:             make(map[MinerID]*monitorState),
+	}
+}
+
+// SetThresholds overrides the default thresholds for a specific device.
+func (m *Monitor) SetThresholds(id MinerID, t MonitorThresholds) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.thresholds[id] = t
+}
+
+func (m *Monitor) thresholdsFor(id MinerID) MonitorThresholds {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if t, ok := m.thresholds[id]; ok {
+		return t
+	}
+	return m.defaultThresholds
+}
+
+func (m *Monitor) stateFor(id MinerID) *monitorState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	st, ok := m.state[id]
+	if !ok {
+		st = &monitorState{}
+		m.state[id] = st
+	}
+	return st
+}
+
+// Start polls every tracked device every interval until ctx is done.
+func (m *Monitor) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.pollOnce(ctx)
+			}
+		}
+	}()
+}
+
+func (m *Monitor) pollOnce(ctx context.Context) {
+	for _, dev := range m.mgr.List() {
+		m.pollDevice(ctx, dev)
+	}
+}
+
+type monitorReading struct {
+	stats     Stats
+	pools     []Pool
+	powerMode PowerMode
+	fanErr    error
+}
+
+func (m *Monitor) pollDevice(ctx context.Context, dev Device) {
+	st := m.stateFor(dev.ID)
+	now := time.Now()
+
+	var reading monitorReading
+	err := m.mgr.WithSession(ctx, dev.ID, func(sess Session) error {
+		stats, err := sess.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		reading.stats = stats
+		if pools, err := sess.Pools(ctx); err == nil {
+			reading.pools = pools
+		}
+		if pm, err := sess.GetPowerMode(ctx); err == nil {
+			reading.powerMode = pm
+		}
+		_, reading.fanErr = sess.GetFan(ctx)
+		return nil
+	})
+
+	if err != nil {
+		if !st.unreachable {
+			st.unreachable = true
+			m.bus.Publish(ctx, Event{
+				Type: EventMinerUnreachable, Time: now, MinerID: dev.ID, Driver: dev.DriverName,
+				Endpoint: dev.Endpoint.Address, Message: err.Error(),
+			})
+		}
+		return
+	}
+	st.unreachable = false
+
+	th := m.thresholdsFor(dev.ID)
+	model := reading.stats.Model
+
+	m.checkHashrateDrop(ctx, dev, th, st, reading.stats, model, now)
+	m.checkTempThreshold(ctx, dev, th, st, reading.stats, model, now)
+	m.checkPoolDisconnected(ctx, dev, st, reading.pools, model, now)
+	m.checkPowerModeChanged(ctx, dev, st, reading.powerMode, model, now)
+	m.checkFanFault(ctx, dev, st, reading.fanErr, model, now)
+}
+
+func (m *Monitor) checkHashrateDrop(ctx context.Context, dev Device, th MonitorThresholds, st *monitorState, stats Stats, model Model, now time.Time) {
+	if th.HashrateDropRatio <= 0 || stats.HashrateAv <= 0 {
+		return
+	}
+	if stats.Hashrate5s.GHS() >= th.HashrateDropRatio*stats.HashrateAv.GHS() {
+		st.hashrateLowSince = time.Time{}
+		return
+	}
+	if st.hashrateLowSince.IsZero() {
+		st.hashrateLowSince = now
+		return
+	}
+	if now.Sub(st.hashrateLowSince) < th.HashrateDropFor {
+		return
+	}
+	m.bus.Publish(ctx, Event{
+		Type: EventHashrateDrop, Time: now, MinerID: dev.ID, Driver: dev.DriverName, Endpoint: dev.Endpoint.Address, Model: model,
+		Message: fmt.Sprintf("hashrate %.2f GH/s below %.0f%% of average %.2f GH/s for over %s",
+			stats.Hashrate5s.GHS(), th.HashrateDropRatio*100, stats.HashrateAv.GHS(), th.HashrateDropFor),
+		Metrics: map[string]float64{"hashrate_5s_ghs": stats.Hashrate5s.GHS(), "hashrate_avg_ghs": stats.HashrateAv.GHS()},
+	})
+	st.hashrateLowSince = now // avoid re-firing every poll while still below threshold
+}
+
+func (m *Monitor) checkTempThreshold(ctx context.Context, dev Device, th MonitorThresholds, st *monitorState, stats Stats, model Model, now time.Time) {
+	if th.MaxTempC <= 0 {
+		return
+	}
+	if stats.TempMax <= th.MaxTempC {
+		st.tempHighSince = time.Time{}
+		return
+	}
+	if st.tempHighSince.IsZero() {
+		st.tempHighSince = now
+		return
+	}
+	if now.Sub(st.tempHighSince) < th.MaxTempFor {
+		return
+	}
+	m.bus.Publish(ctx, Event{
+		Type: EventTempThresholdExceeded, Time: now, MinerID: dev.ID, Driver: dev.DriverName, Endpoint: dev.Endpoint.Address, Model: model,
+		Message: fmt.Sprintf("temp_max %.1fC exceeds threshold %.1fC for over %s", stats.TempMax, th.MaxTempC, th.MaxTempFor),
+		Metrics: map[string]float64{"temp_max_c": stats.TempMax, "threshold_c": th.MaxTempC},
+	})
+	st.tempHighSince = now
+}
+
+func (m *Monitor) checkPoolDisconnected(ctx context.Context, dev Device, st *monitorState, pools []Pool, model Model, now time.Time) {
+	active := ""
+	for _, p := range pools {
+		if p.Active {
+			active = p.URL
+			break
+		}
+	}
+	if active == "" && len(pools) > 0 && st.lastPool != "" {
+		m.bus.Publish(ctx, Event{
+			Type: EventPoolDisconnected, Time: now, MinerID: dev.ID, Driver: dev.DriverName, Endpoint: dev.Endpoint.Address, Model: model,
+			Message: fmt.Sprintf("no active pool (was %s)", st.lastPool),
+		})
+	}
+	st.lastPool = active
+}
+
+func (m *Monitor) checkPowerModeChanged(ctx context.Context, dev Device, st *monitorState, mode PowerMode, model Model, now time.Time) {
+	if st.havePowerMode && st.lastPowerMode != mode.Kind {
+		m.bus.Publish(ctx, Event{
+			Type: EventPowerModeChanged, Time: now, MinerID: dev.ID, Driver: dev.DriverName, Endpoint: dev.Endpoint.Address, Model: model,
+			Message: fmt.Sprintf("power mode changed from %s to %s", st.lastPowerMode, mode.Kind),
+		})
+	}
+	st.lastPowerMode = mode.Kind
+	st.havePowerMode = true
+}
+
+func (m *Monitor) checkFanFault(ctx context.Context, dev Device, st *monitorState, fanErr error, model Model, now time.Time) {
+	if fanErr == nil || IsPermanent(fanErr) {
+		return
+	}
+	m.bus.Publish(ctx, Event{
+		Type: EventFanFault, Time: now, MinerID: dev.ID, Driver: dev.DriverName, Endpoint: dev.Endpoint.Address, Model: model,
+		Message: "fan status unavailable: " + fanErr.Error(),
+	})
+}
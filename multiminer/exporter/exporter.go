@@ -0,0 +1,291 @@
+// Package exporter runs a standalone Prometheus exporter over a fixed set
+// of driver/endpoint targets: it doesn't need a Manager, just a list of
+// multiminer.Driver/multiminer.Endpoint pairs to poll on a schedule and
+// serve back as /metrics.
+package exporter
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+// Defaults used whenever the corresponding Config field is <= 0.
+const (
+	DefaultInterval         = 15 * time.Second
+	DefaultPerTargetTimeout = 5 * time.Second
+	DefaultStaleAfter       = 45 * time.Second
+)
+
+// Target is one endpoint the Exporter polls on a schedule.
+type Target struct {
+	Driver   multiminer.Driver
+	Endpoint multiminer.Endpoint
+}
+
+// Config controls scrape concurrency, per-target timeout, and staleness
+// handling for an Exporter.
+type Config struct {
+	// Interval between scrape rounds. <= 0 uses DefaultInterval.
+	Interval time.Duration
+	// Concurrency caps how many targets are scraped at once within a
+	// round. <= 0 means unbounded (one goroutine per target).
+	Concurrency int
+	// PerTargetTimeout bounds how long a single target's Model/Stats/
+	// Summary/Pools/GetPowerMode/GetFan calls may take, combined.
+	// <= 0 uses DefaultPerTargetTimeout.
+	PerTargetTimeout time.Duration
+	// StaleAfter marks a target `up 0` once its last successful scrape is
+	// older than this, e.g. because the target was removed or has been
+	// unreachable for a while. <= 0 uses DefaultStaleAfter.
+	StaleAfter time.Duration
+}
+
+func (c Config) interval() time.Duration {
+	if c.Interval <= 0 {
+		return DefaultInterval
+	}
+	return c.Interval
+}
+
+func (c Config) perTargetTimeout() time.Duration {
+	if c.PerTargetTimeout <= 0 {
+		return DefaultPerTargetTimeout
+	}
+	return c.PerTargetTimeout
+}
+
+func (c Config) staleAfter() time.Duration {
+	if c.StaleAfter <= 0 {
+		return DefaultStaleAfter
+	}
+	return c.StaleAfter
+}
+
+// snapshot is the cached per-target data a scrape reads from.
+type snapshot struct {
+	at    time.Time
+	up    bool
+	model multiminer.Model
+	stats multiminer.Stats
+	sum   multiminer.Summary
+	power multiminer.PowerMode
+	fan   multiminer.FanConfig
+}
+
+// Exporter is a prometheus.Collector polling a fixed set of Targets on an
+// interval and serving the results in Prometheus text format, labelled by
+// vendor/product/firmware/address so one endpoint can cover a mixed fleet
+// of drivers.
+type Exporter struct {
+	targets []Target
+	cfg     Config
+
+	registry *prometheus.Registry
+
+	upDesc          *prometheus.Desc
+	hashrateDesc    *prometheus.Desc
+	hashrateAvgDesc *prometheus.Desc
+	temperatureDesc *prometheus.Desc
+	uptimeDesc      *prometheus.Desc
+	acceptedDesc    *prometheus.Desc
+	rejectedDesc    *prometheus.Desc
+	powerDesc       *prometheus.Desc
+	fanRPMDesc      *prometheus.Desc
+
+	mu    sync.Mutex
+	cache map[string]snapshot // keyed by Target.Endpoint.Address
+}
+
+// NewExporter creates an Exporter over targets. Call Start to begin
+// scraping and Handler to serve the result.
+func NewExporter(targets []Target, cfg Config) *Exporter {
+	labels := []string{"vendor", "product", "firmware", "address"}
+
+	e := &Exporter{
+		targets:  targets,
+		cfg:      cfg,
+		registry: prometheus.NewRegistry(),
+		cache:    make(map[string]snapshot),
+
+		upDesc:          prometheus.NewDesc("multiminer_up", "Whether the last scrape of this target succeeded (1) or not (0).", labels, nil),
+		hashrateDesc:    prometheus.NewDesc("multiminer_hashrate_ghs", "5s-window hashrate in GH/s.", labels, nil),
+		hashrateAvgDesc: prometheus.NewDesc("multiminer_hashrate_avg_ghs", "Average hashrate in GH/s.", labels, nil),
+		temperatureDesc: prometheus.NewDesc("multiminer_temperature_celsius", "Maximum reported board temperature.", labels, nil),
+		uptimeDesc:      prometheus.NewDesc("multiminer_uptime_seconds", "Reported device uptime in seconds.", labels, nil),
+		acceptedDesc:    prometheus.NewDesc("multiminer_shares_accepted_total", "Accepted shares.", labels, nil),
+		rejectedDesc:    prometheus.NewDesc("multiminer_shares_rejected_total", "Rejected shares.", labels, nil),
+		powerDesc:       prometheus.NewDesc("multiminer_power_watts", "Reported power draw in watts.", labels, nil),
+		fanRPMDesc:      prometheus.NewDesc("multiminer_fan_rpm", "Reported fan speed. NOTE: the Session interface only exposes a fan speed percent, not a tachometer reading, so this is that percent and not a true RPM.", append(append([]string{}, labels...), "fan"), nil),
+	}
+	e.registry.MustRegister(e)
+	return e
+}
+
+// Handler returns the http.Handler that serves this Exporter's registry in
+// the Prometheus text exposition format.
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Start scrapes every target immediately and then every Config.Interval
+// until ctx is done. It returns immediately; scraping happens in the
+// background.
+func (e *Exporter) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(e.cfg.interval())
+		defer ticker.Stop()
+
+		e.scrapeAll(ctx)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				e.scrapeAll(ctx)
+			}
+		}
+	}()
+}
+
+// scrapeAll scrapes every target, at most Config.Concurrency at a time.
+func (e *Exporter) scrapeAll(ctx context.Context) {
+	limit := e.cfg.Concurrency
+	if limit <= 0 || limit > len(e.targets) {
+		limit = len(e.targets)
+	}
+	if limit == 0 {
+		return
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for _, t := range e.targets {
+		t := t
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			e.scrapeOne(ctx, t)
+		}()
+	}
+	wg.Wait()
+}
+
+// scrapeOne opens a session to t, calls Model/Stats/Summary/Pools/
+// GetPowerMode/GetFan, and caches whatever came back. A target is only
+// marked down (up=0) when Open or one of Model/Stats/Summary/Pools fails;
+// GetPowerMode/GetFan aren't implemented by every driver, so their errors
+// don't affect the target's overall health.
+func (e *Exporter) scrapeOne(ctx context.Context, t Target) {
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.perTargetTimeout())
+	defer cancel()
+
+	sess, err := t.Driver.Open(ctx, t.Endpoint)
+	if err != nil {
+		e.recordDown(t.Endpoint.Address)
+		return
+	}
+	defer sess.Close()
+
+	snap := snapshot{at: time.Now(), up: true}
+
+	if model, err := sess.Model(ctx); err == nil {
+		snap.model = model
+	} else {
+		snap.up = false
+	}
+	if stats, err := sess.Stats(ctx); err == nil {
+		snap.stats = stats
+	} else {
+		snap.up = false
+	}
+	if sum, err := sess.Summary(ctx); err == nil {
+		snap.sum = sum
+	} else {
+		snap.up = false
+	}
+	if _, err := sess.Pools(ctx); err != nil {
+		snap.up = false
+	}
+	if power, err := sess.GetPowerMode(ctx); err == nil {
+		snap.power = power
+	}
+	if fan, err := sess.GetFan(ctx); err == nil {
+		snap.fan = fan
+	}
+
+	e.mu.Lock()
+	e.cache[t.Endpoint.Address] = snap
+	e.mu.Unlock()
+}
+
+// recordDown marks address down without disturbing its last known model/
+// stats, so a transient outage doesn't blank out labels on recovery.
+func (e *Exporter) recordDown(address string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	snap := e.cache[address]
+	snap.at = time.Now()
+	snap.up = false
+	e.cache[address] = snap
+}
+
+// Describe implements prometheus.Collector.
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.upDesc
+	ch <- e.hashrateDesc
+	ch <- e.hashrateAvgDesc
+	ch <- e.temperatureDesc
+	ch <- e.uptimeDesc
+	ch <- e.acceptedDesc
+	ch <- e.rejectedDesc
+	ch <- e.powerDesc
+	ch <- e.fanRPMDesc
+}
+
+// Collect implements prometheus.Collector, reading each target's cached
+// snapshot and treating one older than Config.StaleAfter as down even if
+// its last scrape happened to succeed.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.Lock()
+	cache := make(map[string]snapshot, len(e.cache))
+	for k, v := range e.cache {
+		cache[k] = v
+	}
+	e.mu.Unlock()
+
+	staleBefore := time.Now().Add(-e.cfg.staleAfter())
+
+	for _, t := range e.targets {
+		snap := cache[t.Endpoint.Address]
+		labels := []string{snap.model.Vendor, snap.model.Product, snap.model.Firmware, t.Endpoint.Address}
+
+		up := 0.0
+		if snap.up && snap.at.After(staleBefore) {
+			up = 1
+		}
+		ch <- prometheus.MustNewConstMetric(e.upDesc, prometheus.GaugeValue, up, labels...)
+		if up == 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(e.hashrateDesc, prometheus.GaugeValue, snap.stats.Hashrate5s.GHS(), labels...)
+		ch <- prometheus.MustNewConstMetric(e.hashrateAvgDesc, prometheus.GaugeValue, snap.stats.HashrateAv.GHS(), labels...)
+		ch <- prometheus.MustNewConstMetric(e.temperatureDesc, prometheus.GaugeValue, snap.stats.TempMax, labels...)
+		ch <- prometheus.MustNewConstMetric(e.uptimeDesc, prometheus.GaugeValue, float64(snap.stats.UptimeSec), labels...)
+		ch <- prometheus.MustNewConstMetric(e.acceptedDesc, prometheus.CounterValue, float64(snap.sum.Accepted), labels...)
+		ch <- prometheus.MustNewConstMetric(e.rejectedDesc, prometheus.CounterValue, float64(snap.sum.Rejected), labels...)
+		ch <- prometheus.MustNewConstMetric(e.powerDesc, prometheus.GaugeValue, float64(snap.power.Watts), labels...)
+
+		fanLabels := append(append([]string{}, labels...), "0")
+		ch <- prometheus.MustNewConstMetric(e.fanRPMDesc, prometheus.GaugeValue, float64(snap.fan.SpeedPct), fanLabels...)
+	}
+}
@@ -0,0 +1,287 @@
+package multiminer
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// FleetOptions configures a Fleet's concurrency, rate limiting, retry, and
+// per-operation timeout behavior.
+type FleetOptions struct {
+	// Parallelism caps how many endpoints are operated on concurrently. 0
+	// means unbounded (one goroutine per endpoint).
+	Parallelism int
+	// PerHostRPS caps how often any single endpoint may be hit. 0 disables
+	// per-host rate limiting.
+	PerHostRPS float64
+	// GlobalRPS caps the aggregate request rate across the whole fleet. 0
+	// disables global rate limiting.
+	GlobalRPS float64
+	// PerOpTimeout, if non-zero, bounds each attempt at a single endpoint's
+	// operation on its own; every retry gets a fresh PerOpTimeout.
+	PerOpTimeout time.Duration
+	// MaxRetries is how many additional attempts follow an initial failure.
+	// A Permanent-classified error (see MultiMinerError.Classification)
+	// skips retries regardless of this setting.
+	MaxRetries int
+	// RetryBaseBackoff is the delay before the first retry; RetryMaxBackoff
+	// caps it after doubling on each subsequent attempt. Actual sleeps are
+	// jittered to avoid a thundering herd across a large fleet.
+	RetryBaseBackoff time.Duration
+	RetryMaxBackoff  time.Duration
+}
+
+// FleetResult is the aggregate outcome of a Fleet operation across many
+// endpoints: a partial-success map rather than a single all-or-nothing
+// error, keyed by each endpoint's Address.
+type FleetResult[T any] struct {
+	Successes map[string]T
+	Failures  map[string]error
+}
+
+// rateGate enforces a minimum interval between successive Wait calls. A nil
+// *rateGate never blocks, so callers can treat "no limit configured" and
+// "limit configured" uniformly.
+type rateGate struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateGate(rps float64) *rateGate {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateGate{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+func (g *rateGate) wait(ctx context.Context) error {
+	if g == nil {
+		return nil
+	}
+
+	g.mu.Lock()
+	now := time.Now()
+	if g.next.Before(now) {
+		g.next = now
+	}
+	wait := g.next.Sub(now)
+	g.next = g.next.Add(g.interval)
+	g.mu.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// jitter returns a random duration in [d/2, d*3/2), to keep a large fleet's
+// retries from synchronizing into a thundering herd.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d)))
+}
+
+// Fleet drives Driver.Open + Session operations across many Endpoints
+// concurrently, independent of Manager/device registration. It bounds
+// concurrency with a worker pool, rate-limits per-host and globally, retries
+// transient failures with jittered exponential backoff, and bounds each
+// attempt with a timeout — so operators can run one-off bulk operations
+// across thousands of miners without hand-rolling any of that per call site.
+type Fleet struct {
+	driver Driver
+	opt    FleetOptions
+	global *rateGate
+
+	hostMu sync.Mutex
+	hosts  map[string]*rateGate
+}
+
+// NewFleet creates a Fleet that opens sessions via driver. Zero-value
+// fields in opt take the sensible "unbounded"/"disabled" meaning documented
+// on FleetOptions, except RetryBaseBackoff/RetryMaxBackoff which fall back
+// to 250ms/5s so a caller that only sets MaxRetries still gets backoff.
+func NewFleet(driver Driver, opt FleetOptions) *Fleet {
+	if opt.RetryBaseBackoff <= 0 {
+		opt.RetryBaseBackoff = 250 * time.Millisecond
+	}
+	if opt.RetryMaxBackoff <= 0 {
+		opt.RetryMaxBackoff = 5 * time.Second
+	}
+
+	return &Fleet{
+		driver: driver,
+		opt:    opt,
+		global: newRateGate(opt.GlobalRPS),
+		hosts:  make(map[string]*rateGate),
+	}
+}
+
+func (f *Fleet) hostGate(address string) *rateGate {
+	if f.opt.PerHostRPS <= 0 {
+		return nil
+	}
+	f.hostMu.Lock()
+	defer f.hostMu.Unlock()
+	g, ok := f.hosts[address]
+	if !ok {
+		g = newRateGate(f.opt.PerHostRPS)
+		f.hosts[address] = g
+	}
+	return g
+}
+
+// fleetRun opens a session to ep (honoring rate limits and PerOpTimeout),
+// runs fn, and retries on failure with jittered exponential backoff up to
+// opt.MaxRetries times. A Permanent-classified error short-circuits retries.
+func fleetRun[T any](ctx context.Context, f *Fleet, ep Endpoint, fn func(context.Context, Session) (T, error)) (T, error) {
+	var zero T
+
+	if err := f.global.wait(ctx); err != nil {
+		return zero, err
+	}
+	if err := f.hostGate(ep.Address).wait(ctx); err != nil {
+		return zero, err
+	}
+
+	attempts := f.opt.MaxRetries + 1
+	backoff := f.opt.RetryBaseBackoff
+	var lastErr error
+
+	for attempt := 0; attempt < attempts; attempt++ {
+		opCtx := ctx
+		var cancel context.CancelFunc
+		if f.opt.PerOpTimeout > 0 {
+			opCtx, cancel = context.WithTimeout(ctx, f.opt.PerOpTimeout)
+		}
+
+		result, err := func() (T, error) {
+			sess, openErr := f.driver.Open(opCtx, ep)
+			if openErr != nil {
+				var zero T
+				return zero, openErr
+			}
+			defer sess.Close()
+			return fn(opCtx, sess)
+		}()
+		if cancel != nil {
+			cancel()
+		}
+		if err == nil {
+			return result, nil
+		}
+		lastErr = err
+
+		if IsPermanent(err) || attempt == attempts-1 {
+			break
+		}
+
+		t := time.NewTimer(jitter(backoff))
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return zero, ctx.Err()
+		case <-t.C:
+		}
+		backoff = nextBackoff(backoff, f.opt.RetryMaxBackoff)
+	}
+
+	return zero, lastErr
+}
+
+// fleetFanOut runs fn against every endpoint in eps concurrently, bounded by
+// opt.Parallelism, collecting successes/failures into a FleetResult.
+func fleetFanOut[T any](ctx context.Context, f *Fleet, eps []Endpoint, fn func(context.Context, Session) (T, error)) FleetResult[T] {
+	result := FleetResult[T]{
+		Successes: make(map[string]T),
+		Failures:  make(map[string]error),
+	}
+	if len(eps) == 0 {
+		return result
+	}
+
+	parallelism := f.opt.Parallelism
+	if parallelism <= 0 || parallelism > len(eps) {
+		parallelism = len(eps)
+	}
+
+	var mu sync.Mutex
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+
+	for _, ep := range eps {
+		ep := ep
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			val, err := fleetRun(ctx, f, ep, fn)
+
+			mu.Lock()
+			if err != nil {
+				result.Failures[ep.Address] = err
+			} else {
+				result.Successes[ep.Address] = val
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return result
+}
+
+// Stats fetches Stats from every endpoint concurrently.
+func (f *Fleet) Stats(ctx context.Context, eps []Endpoint) FleetResult[Stats] {
+	return fleetFanOut(ctx, f, eps, func(ctx context.Context, sess Session) (Stats, error) {
+		return sess.Stats(ctx)
+	})
+}
+
+// Summary fetches Summary from every endpoint concurrently.
+func (f *Fleet) Summary(ctx context.Context, eps []Endpoint) FleetResult[Summary] {
+	return fleetFanOut(ctx, f, eps, func(ctx context.Context, sess Session) (Summary, error) {
+		return sess.Summary(ctx)
+	})
+}
+
+// SetPowerMode applies mode to every endpoint concurrently.
+func (f *Fleet) SetPowerMode(ctx context.Context, eps []Endpoint, mode PowerMode) FleetResult[struct{}] {
+	return fleetFanOut(ctx, f, eps, func(ctx context.Context, sess Session) (struct{}, error) {
+		return struct{}{}, sess.SetPowerMode(ctx, mode)
+	})
+}
+
+// SetFan applies fan to every endpoint concurrently.
+func (f *Fleet) SetFan(ctx context.Context, eps []Endpoint, fan FanConfig) FleetResult[struct{}] {
+	return fleetFanOut(ctx, f, eps, func(ctx context.Context, sess Session) (struct{}, error) {
+		return struct{}{}, sess.SetFan(ctx, fan)
+	})
+}
+
+// SwitchPool switches every endpoint to poolID concurrently.
+func (f *Fleet) SwitchPool(ctx context.Context, eps []Endpoint, poolID int64) FleetResult[struct{}] {
+	return fleetFanOut(ctx, f, eps, func(ctx context.Context, sess Session) (struct{}, error) {
+		return struct{}{}, sess.SwitchPool(ctx, poolID)
+	})
+}
+
+// Restart restarts every endpoint concurrently.
+func (f *Fleet) Restart(ctx context.Context, eps []Endpoint) FleetResult[struct{}] {
+	return fleetFanOut(ctx, f, eps, func(ctx context.Context, sess Session) (struct{}, error) {
+		return struct{}{}, sess.Restart(ctx)
+	})
+}
@@ -0,0 +1,265 @@
+package multiminer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Group is a named collection of devices (a farm, room, or rack) that fan-out
+// operations can target as a unit.
+type Group struct {
+	Name    string
+	Members map[MinerID]struct{}
+}
+
+// GroupInfo is a safe DTO for API responses.
+type GroupInfo struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// FanOutOptions controls how WithGroupSessions spreads work across a group's members.
+type FanOutOptions struct {
+	// Parallelism caps the number of devices operated on concurrently. 0 means unbounded (one goroutine per member).
+	Parallelism int
+	// PerDeviceTimeout, if non-zero, bounds how long a single device's operation may run.
+	PerDeviceTimeout time.Duration
+	// FailFast cancels in-flight and not-yet-started operations as soon as one member fails.
+	FailFast bool
+}
+
+// GroupResult reports the outcome of a group fan-out operation.
+type GroupResult struct {
+	// Err is set if the fan-out couldn't run at all, e.g. the group doesn't exist.
+	Err error
+	// Results maps each member to the error its operation returned (nil on success).
+	Results   map[MinerID]error
+	Succeeded int
+	Failed    int
+}
+
+// CreateGroup creates (or replaces) a named group with the given members.
+func (m *Manager) CreateGroup(name string, ids []MinerID) error {
+	g := &Group{Name: name, Members: make(map[MinerID]struct{}, len(ids))}
+	for _, id := range ids {
+		g.Members[id] = struct{}{}
+	}
+
+	m.groupsMu.Lock()
+	m.groups[name] = g
+	m.groupsMu.Unlock()
+
+	return m.persistGroup(g)
+}
+
+// AddToGroup adds a device to an existing group.
+func (m *Manager) AddToGroup(name string, id MinerID) error {
+	m.groupsMu.Lock()
+	g, ok := m.groups[name]
+	if !ok {
+		m.groupsMu.Unlock()
+		return NewNotFoundError("group not found")
+	}
+	g.Members[id] = struct{}{}
+	m.groupsMu.Unlock()
+
+	return m.persistGroup(g)
+}
+
+// RemoveFromGroup removes a device from a group.
+func (m *Manager) RemoveFromGroup(name string, id MinerID) error {
+	m.groupsMu.Lock()
+	g, ok := m.groups[name]
+	if !ok {
+		m.groupsMu.Unlock()
+		return NewNotFoundError("group not found")
+	}
+	delete(g.Members, id)
+	m.groupsMu.Unlock()
+
+	return m.persistGroup(g)
+}
+
+// Groups lists all known groups.
+func (m *Manager) Groups() []GroupInfo {
+	m.groupsMu.RLock()
+	defer m.groupsMu.RUnlock()
+
+	out := make([]GroupInfo, 0, len(m.groups))
+	for _, g := range m.groups {
+		members := make([]string, 0, len(g.Members))
+		for id := range g.Members {
+			members = append(members, string(id))
+		}
+		out = append(out, GroupInfo{Name: g.Name, Members: members})
+	}
+	return out
+}
+
+func (m *Manager) persistGroup(g *Group) error {
+	if m.store == nil {
+		return nil
+	}
+	members := make([]MinerID, 0, len(g.Members))
+	for id := range g.Members {
+		members = append(members, id)
+	}
+	return m.store.PutGroup(GroupRecord{Name: g.Name, Members: members})
+}
+
+// loadGroupsFromStore replays persisted group membership on startup.
+func (m *Manager) loadGroupsFromStore() {
+	records, err := m.store.ListGroups()
+	if err != nil {
+		LogWarn(context.Background(), "failed to load persisted groups", F("error", err))
+		return
+	}
+
+	m.groupsMu.Lock()
+	defer m.groupsMu.Unlock()
+	for _, rec := range records {
+		g := &Group{Name: rec.Name, Members: make(map[MinerID]struct{}, len(rec.Members))}
+		for _, id := range rec.Members {
+			g.Members[id] = struct{}{}
+		}
+		m.groups[rec.Name] = g
+	}
+}
+
+// WithGroupSessions fans fn out across every member of the named group,
+// bounded by opts.Parallelism, and reports per-member results.
+func (m *Manager) WithGroupSessions(ctx context.Context, name string, fn func(MinerID, Session) error, opts FanOutOptions) GroupResult {
+	m.groupsMu.RLock()
+	g, ok := m.groups[name]
+	var members []MinerID
+	if ok {
+		members = make([]MinerID, 0, len(g.Members))
+		for id := range g.Members {
+			members = append(members, id)
+		}
+	}
+	m.groupsMu.RUnlock()
+
+	if !ok {
+		return GroupResult{Err: NewNotFoundError("group not found")}
+	}
+
+	return m.fanOut(ctx, members, fn, opts)
+}
+
+// fanOut runs fn against every id in ids, bounded by opts.Parallelism, and
+// collects per-device results. It's the shared worker-pool core behind
+// WithGroupSessions and WithSelectedSessions.
+func (m *Manager) fanOut(ctx context.Context, ids []MinerID, fn func(MinerID, Session) error, opts FanOutOptions) GroupResult {
+	result := GroupResult{Results: make(map[MinerID]error, len(ids))}
+	if len(ids) == 0 {
+		return result
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = len(ids)
+	}
+
+	fanCtx := ctx
+	var cancel context.CancelFunc
+	if opts.FailFast {
+		fanCtx, cancel = context.WithCancel(ctx)
+		defer cancel()
+	}
+
+	sem := make(chan struct{}, parallelism)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, id := range ids {
+		id := id
+
+		select {
+		case <-fanCtx.Done():
+			mu.Lock()
+			result.Results[id] = fanCtx.Err()
+			result.Failed++
+			mu.Unlock()
+			continue
+		default:
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			devCtx := fanCtx
+			if opts.PerDeviceTimeout > 0 {
+				var dcancel context.CancelFunc
+				devCtx, dcancel = context.WithTimeout(fanCtx, opts.PerDeviceTimeout)
+				defer dcancel()
+			}
+
+			err := m.WithSession(devCtx, id, func(sess Session) error { return fn(id, sess) })
+
+			mu.Lock()
+			result.Results[id] = err
+			if err != nil {
+				result.Failed++
+				if opts.FailFast && cancel != nil {
+					cancel()
+				}
+			} else {
+				result.Succeeded++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	return result
+}
+
+// SwitchAllPools switches every member of a group to the given pool.
+func (m *Manager) SwitchAllPools(ctx context.Context, group string, poolID int64, opts FanOutOptions) GroupResult {
+	return m.WithGroupSessions(ctx, group, func(id MinerID, sess Session) error {
+		return sess.SwitchPool(ctx, poolID)
+	}, opts)
+}
+
+// SetAllPowerMode applies a PowerMode to every member of a group.
+func (m *Manager) SetAllPowerMode(ctx context.Context, group string, mode PowerMode, opts FanOutOptions) GroupResult {
+	return m.WithGroupSessions(ctx, group, func(id MinerID, sess Session) error {
+		return sess.SetPowerMode(ctx, mode)
+	}, opts)
+}
+
+// AggregateStats sums hashrates and averages temperatures across a group's members.
+func (m *Manager) AggregateStats(ctx context.Context, group string, opts FanOutOptions) (Stats, GroupResult) {
+	var mu sync.Mutex
+	var agg Stats
+	var tempSum float64
+	var tempCount int
+
+	result := m.WithGroupSessions(ctx, group, func(id MinerID, sess Session) error {
+		st, err := sess.Stats(ctx)
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		agg.Hashrate5s += st.Hashrate5s
+		agg.HashrateAv += st.HashrateAv
+		if st.TempMax > 0 {
+			tempSum += st.TempMax
+			tempCount++
+		}
+		mu.Unlock()
+		return nil
+	}, opts)
+
+	if tempCount > 0 {
+		agg.TempMax = tempSum / float64(tempCount)
+	}
+
+	return agg, result
+}
@@ -0,0 +1,143 @@
+package multiminer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HashrateValue is a canonical hashrate stored as whole hashes per second.
+// Every driver used to juggle ad-hoc GH/s floats and hand-rolled unit
+// parsing (with bugs like silently treating MH/s as GH/s); this type is the
+// single place unit conversion and parsing happens.
+type HashrateValue uint64
+
+// Scale constants, in hashes per second.
+const (
+	hashesPerKH HashrateValue = 1e3
+	hashesPerMH HashrateValue = 1e6
+	hashesPerGH HashrateValue = 1e9
+	hashesPerTH HashrateValue = 1e12
+	hashesPerPH HashrateValue = 1e15
+	hashesPerEH HashrateValue = 1e18
+)
+
+// HashrateFromGHS converts a GH/s float, the unit nearly every driver's raw
+// API already reports in, to a HashrateValue.
+func HashrateFromGHS(ghs float64) HashrateValue {
+	if ghs <= 0 {
+		return 0
+	}
+	return HashrateValue(ghs * float64(hashesPerGH))
+}
+
+// GHS returns v as a GH/s float, for code that still needs a plain number
+// (Prometheus gauges, threshold math, JSON back-compat).
+func (v HashrateValue) GHS() float64 {
+	return float64(v) / float64(hashesPerGH)
+}
+
+// hashrateUnit is one entry of the unit table used for both parsing and
+// formatting, ordered largest-to-smallest so String can pick the largest
+// unit that keeps the value >= 1.
+type hashrateUnit struct {
+	scale HashrateValue
+	names []string // first name is canonical, used by String
+}
+
+var hashrateUnits = []hashrateUnit{
+	{hashesPerEH, []string{"EH/s", "EH", "E"}},
+	{hashesPerPH, []string{"PH/s", "PH", "P"}},
+	{hashesPerTH, []string{"TH/s", "TH", "T"}},
+	{hashesPerGH, []string{"GH/s", "GH", "G"}},
+	{hashesPerMH, []string{"MH/s", "MH", "M"}},
+	{hashesPerKH, []string{"KH/s", "KH", "K"}},
+	{1, []string{"H/s", "H"}},
+}
+
+// Equihash-family units report solutions/s rather than hashes/s. There's no
+// fixed conversion between Sol/s and H/s, so these are kept as their own
+// unit entries at the same scale factors as their H/s counterparts, which
+// is the convention the miners that report them (e.g. some iPollo
+// firmwares) already use.
+var solUnits = []hashrateUnit{
+	{hashesPerKH, []string{"kSol/s", "KSol/s", "kSol"}},
+	{1, []string{"Sol/s", "Sol"}},
+}
+
+var hashrateRe = regexp.MustCompile(`^\s*([0-9]+(?:[.,][0-9]+)?)\s*([A-Za-z]*/?[sS]|[A-Za-z]+)\s*$`)
+
+// ParseHashrateString parses a hashrate string such as "12.5 TH/s",
+// "96,3 MH/s" (comma decimal separator), or "120 kSol/s" into a
+// HashrateValue. Matching is case-insensitive and tolerant of a missing or
+// inconsistent "/s" suffix (e.g. "TH" alongside "TH/s").
+func ParseHashrateString(s string) (HashrateValue, error) {
+	m := hashrateRe.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("multiminer: invalid hashrate string %q", s)
+	}
+
+	numStr := strings.Replace(m[1], ",", ".", 1)
+	num, err := strconv.ParseFloat(numStr, 64)
+	if err != nil {
+		return 0, fmt.Errorf("multiminer: invalid hashrate number %q: %w", m[1], err)
+	}
+
+	unitStr := m[2]
+	for _, u := range solUnits {
+		if unitMatches(u, unitStr) {
+			return HashrateValue(num * float64(u.scale)), nil
+		}
+	}
+	for _, u := range hashrateUnits {
+		if unitMatches(u, unitStr) {
+			return HashrateValue(num * float64(u.scale)), nil
+		}
+	}
+	return 0, fmt.Errorf("multiminer: unrecognized hashrate unit %q", unitStr)
+}
+
+func unitMatches(u hashrateUnit, s string) bool {
+	for _, name := range u.names {
+		if strings.EqualFold(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats v using the largest unit that keeps the displayed value
+// at or above 1, e.g. "12.50 TH/s".
+func (v HashrateValue) String() string {
+	if v == 0 {
+		return "0 H/s"
+	}
+	for _, u := range hashrateUnits {
+		if v >= u.scale {
+			return fmt.Sprintf("%.2f %s", float64(v)/float64(u.scale), u.names[0])
+		}
+	}
+	return fmt.Sprintf("%d H/s", uint64(v))
+}
+
+// MarshalJSON encodes v as a GH/s float, matching the JSON shape older
+// callers of Stats/Summary already depend on.
+func (v HashrateValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.GHS())
+}
+
+// UnmarshalJSON decodes a GH/s float, the inverse of MarshalJSON.
+func (v *HashrateValue) UnmarshalJSON(data []byte) error {
+	var ghs float64
+	if err := json.Unmarshal(data, &ghs); err != nil {
+		return err
+	}
+	if ghs < 0 || math.IsNaN(ghs) || math.IsInf(ghs, 0) {
+		return fmt.Errorf("multiminer: invalid hashrate GH/s value %v", ghs)
+	}
+	*v = HashrateFromGHS(ghs)
+	return nil
+}
@@ -0,0 +1,249 @@
+// Package httpx is a driver-agnostic HTTP transport for multiminer's
+// HTTP-speaking drivers: one shared, keep-alive *http.Client per device
+// instead of one per call, retry-with-jittered-backoff for transient 5xx/EOF
+// failures, optional mTLS client certs, and an auth hook for devices that
+// gate their API behind a token or cookie that expires and needs a
+// re-login on 401.
+package httpx
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Config tunes the transport a Client builds and the retry policy applied
+// around every request. The zero value is a plain HTTP client with a 3s
+// timeout, two idle connections per host, and no retries.
+type Config struct {
+	// Scheme is "http" or "https". Empty means "http".
+	Scheme string
+
+	Timeout             time.Duration
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+
+	InsecureSkipVerify bool
+	ClientCertFile     string
+	ClientKeyFile      string
+
+	// Retries is how many additional attempts a request gets after a
+	// transient failure (connection error, EOF, or 5xx) before Do gives up.
+	Retries int
+	// Backoff is the base delay before the first retry; each subsequent
+	// retry doubles it with up to 50% jitter added. <= 0 uses 200ms.
+	Backoff time.Duration
+
+	// Login, if set, is called the first time a Client is used and again
+	// the first time a response comes back 401, then the request is
+	// retried with whatever auth Login applied via Client.SetAuthHeader.
+	// Login must send its own request(s) via Client.Raw, not Client.Do —
+	// Do calls Login to authenticate, so a Login that calls Do would
+	// recurse forever.
+	Login func(ctx context.Context, c *Client) error
+}
+
+func (c Config) scheme() string {
+	if c.Scheme == "" {
+		return "http"
+	}
+	return c.Scheme
+}
+
+func (c Config) timeout() time.Duration {
+	if c.Timeout <= 0 {
+		return 3 * time.Second
+	}
+	return c.Timeout
+}
+
+func (c Config) maxIdleConnsPerHost() int {
+	if c.MaxIdleConnsPerHost <= 0 {
+		return 2
+	}
+	return c.MaxIdleConnsPerHost
+}
+
+// idleConnTimeout defaults to 5 minutes, matching ConnectionPool's default
+// idle session lifetime so an HTTP driver's transport-level connections and
+// the multiminer.Session pooling them age out together.
+func (c Config) idleConnTimeout() time.Duration {
+	if c.IdleConnTimeout <= 0 {
+		return 5 * time.Minute
+	}
+	return c.IdleConnTimeout
+}
+
+func (c Config) backoff() time.Duration {
+	if c.Backoff <= 0 {
+		return 200 * time.Millisecond
+	}
+	return c.Backoff
+}
+
+// Client is a shared HTTP client for a single device: one *http.Client (and
+// therefore one connection pool) reused across every call, with retry and
+// optional re-login on top.
+type Client struct {
+	cfg  Config
+	host string
+	http *http.Client
+
+	mu            sync.Mutex
+	authenticated bool
+	authHeader    string
+	authValue     string
+}
+
+// New builds a Client that talks to host (e.g. "192.168.1.50:80") using cfg.
+func New(host string, cfg Config) (*Client, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cfg.InsecureSkipVerify}
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("httpx: failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &Client{
+		cfg:  cfg,
+		host: host,
+		http: &http.Client{
+			Timeout: cfg.timeout(),
+			Transport: &http.Transport{
+				TLSClientConfig:     tlsConfig,
+				MaxIdleConnsPerHost: cfg.maxIdleConnsPerHost(),
+				IdleConnTimeout:     cfg.idleConnTimeout(),
+			},
+		},
+	}, nil
+}
+
+// URL joins the client's scheme and host with path, e.g.
+// URL("/api/status") -> "http://192.168.1.50:80/api/status".
+func (c *Client) URL(path string) string {
+	return fmt.Sprintf("%s://%s%s", c.cfg.scheme(), c.host, path)
+}
+
+// SetAuthHeader records a header Do attaches to every subsequent request,
+// e.g. after Config.Login extracts a session token from a login response.
+// It also marks the client authenticated, so Do won't call Login again
+// until a request comes back 401.
+func (c *Client) SetAuthHeader(header, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.authHeader = header
+	c.authValue = value
+	c.authenticated = true
+}
+
+// Do performs req, applying any auth header Config.Login installed,
+// retrying transient failures (connection errors and 5xx responses) with
+// jittered exponential backoff up to cfg.Retries times, and re-running
+// Config.Login once if a response comes back 401.
+//
+// req must be safe to send more than once: Do neither reads nor replaces
+// req.Body, so callers passing a request with a body are responsible for
+// it being replayable (e.g. nil, for the GET-only calls this package is
+// built for so far).
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if c.cfg.Login != nil {
+		if err := c.ensureAuthenticated(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	relogged := false
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		c.applyAuthHeader(req)
+		resp, err := c.http.Do(req.WithContext(ctx))
+
+		if err == nil && resp.StatusCode == http.StatusUnauthorized && c.cfg.Login != nil && !relogged {
+			resp.Body.Close()
+			relogged = true
+			c.mu.Lock()
+			c.authenticated = false
+			c.mu.Unlock()
+			if err := c.ensureAuthenticated(ctx); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = fmt.Errorf("httpx: server responded %s", resp.Status)
+			resp.Body.Close()
+		}
+
+		if attempt >= c.cfg.Retries {
+			return nil, lastErr
+		}
+		if werr := sleepBackoff(ctx, c.cfg.backoff(), attempt); werr != nil {
+			return nil, werr
+		}
+	}
+}
+
+// Raw performs req using the client's shared transport exactly once, with
+// no retry and no auth/login handling. It's the primitive Config.Login
+// should use to make its own request(s) without recursing back into Do.
+func (c *Client) Raw(req *http.Request) (*http.Response, error) {
+	return c.http.Do(req)
+}
+
+func (c *Client) applyAuthHeader(req *http.Request) {
+	c.mu.Lock()
+	header, value := c.authHeader, c.authValue
+	c.mu.Unlock()
+	if header != "" {
+		req.Header.Set(header, value)
+	}
+}
+
+// ensureAuthenticated runs Config.Login if this Client hasn't authenticated
+// yet (or was just marked unauthenticated after a 401).
+func (c *Client) ensureAuthenticated(ctx context.Context) error {
+	c.mu.Lock()
+	already := c.authenticated
+	c.mu.Unlock()
+	if already {
+		return nil
+	}
+	if err := c.cfg.Login(ctx, c); err != nil {
+		return fmt.Errorf("httpx: login failed: %w", err)
+	}
+	return nil
+}
+
+// sleepBackoff waits before retry attempt n (0-based), with up to 50%
+// jitter so a fleet of clients retrying at once doesn't do so in lockstep.
+// Returns ctx.Err() if ctx is canceled first.
+func sleepBackoff(ctx context.Context, base time.Duration, n int) error {
+	delay := base << uint(n)
+	delay += time.Duration(rand.Int63n(int64(delay)/2 + 1))
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,84 @@
+package httpx
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientRetriesTransientServerErrors(t *testing.T) {
+	var calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.Listener.Addr().String(), Config{Retries: 3})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, client.URL("/"), nil)
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if calls != 3 {
+		t.Errorf("expected 3 attempts before success, got %d", calls)
+	}
+}
+
+func TestClientReLoginsOn401(t *testing.T) {
+	var logins, calls int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			logins++
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		calls++
+		if r.Header.Get("auth") != "tok" || calls == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client, err := New(srv.Listener.Addr().String(), Config{
+		Retries: 2,
+		Login: func(ctx context.Context, c *Client) error {
+			req, _ := http.NewRequest(http.MethodPost, c.URL("/login"), nil)
+			resp, err := c.Raw(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			c.SetAuthHeader("auth", "tok")
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, client.URL("/api/status"), nil)
+	resp, err := client.Do(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if logins != 2 {
+		t.Errorf("expected the initial login plus one re-login after the 401, got %d", logins)
+	}
+}
@@ -0,0 +1,107 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+// Sampler wraps a Logger and drops repeated messages past a per-key burst
+// within a rolling window, so a device stuck in a reconnect loop doesn't
+// flood logs with the same "connection failed" line every poll. Keys are
+// the message text plus any "miner_id" or "endpoint" field passed to that
+// log call. Fields attached with WithFields are not part of the key, so
+// loggers derived for different devices share one window per message
+// unless each call also passes its own "miner_id" or "endpoint" field.
+type Sampler struct {
+	inner multiminer.Logger
+	every time.Duration
+	burst int
+	state *samplerState
+}
+
+// samplerState is shared (via pointer) between a Sampler and every logger
+// WithFields derives from it, so sampling windows stay per-key across the
+// whole family of derived loggers rather than resetting on every
+// WithFields call.
+type samplerState struct {
+	mu      sync.Mutex
+	windows map[string]*sampleWindow
+}
+
+type sampleWindow struct {
+	start time.Time
+	count int
+}
+
+// NewSampler wraps inner, allowing at most burst log calls per distinct key
+// within every; further calls in the same window are dropped silently.
+// burst <= 0 disables sampling (every call passes through).
+func NewSampler(inner multiminer.Logger, every time.Duration, burst int) *Sampler {
+	return &Sampler{
+		inner: inner,
+		every: every,
+		burst: burst,
+		state: &samplerState{windows: make(map[string]*sampleWindow)},
+	}
+}
+
+func (s *Sampler) allow(key string) bool {
+	if s.burst <= 0 {
+		return true
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	now := time.Now()
+	w, ok := s.state.windows[key]
+	if !ok || now.Sub(w.start) > s.every {
+		s.state.windows[key] = &sampleWindow{start: now, count: 1}
+		return true
+	}
+
+	w.count++
+	return w.count <= s.burst
+}
+
+func sampleKey(msg string, fields []multiminer.Field) string {
+	key := msg
+	for _, f := range fields {
+		if f.Key == "miner_id" || f.Key == "endpoint" {
+			key += "|" + f.Key + "=" + fmt.Sprintf("%v", f.Value)
+		}
+	}
+	return key
+}
+
+func (s *Sampler) Debug(ctx context.Context, msg string, fields ...multiminer.Field) {
+	if s.allow(sampleKey(msg, fields)) {
+		s.inner.Debug(ctx, msg, fields...)
+	}
+}
+
+func (s *Sampler) Info(ctx context.Context, msg string, fields ...multiminer.Field) {
+	if s.allow(sampleKey(msg, fields)) {
+		s.inner.Info(ctx, msg, fields...)
+	}
+}
+
+func (s *Sampler) Warn(ctx context.Context, msg string, fields ...multiminer.Field) {
+	if s.allow(sampleKey(msg, fields)) {
+		s.inner.Warn(ctx, msg, fields...)
+	}
+}
+
+func (s *Sampler) Error(ctx context.Context, msg string, fields ...multiminer.Field) {
+	if s.allow(sampleKey(msg, fields)) {
+		s.inner.Error(ctx, msg, fields...)
+	}
+}
+
+func (s *Sampler) WithFields(fields ...multiminer.Field) multiminer.Logger {
+	return &Sampler{inner: s.inner.WithFields(fields...), every: s.every, burst: s.burst, state: s.state}
+}
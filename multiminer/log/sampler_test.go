@@ -0,0 +1,83 @@
+package log
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+type recordingLogger struct {
+	msgs []string
+}
+
+func (r *recordingLogger) Debug(ctx context.Context, msg string, fields ...multiminer.Field) {
+	r.msgs = append(r.msgs, msg)
+}
+func (r *recordingLogger) Info(ctx context.Context, msg string, fields ...multiminer.Field) {
+	r.msgs = append(r.msgs, msg)
+}
+func (r *recordingLogger) Warn(ctx context.Context, msg string, fields ...multiminer.Field) {
+	r.msgs = append(r.msgs, msg)
+}
+func (r *recordingLogger) Error(ctx context.Context, msg string, fields ...multiminer.Field) {
+	r.msgs = append(r.msgs, msg)
+}
+func (r *recordingLogger) WithFields(fields ...multiminer.Field) multiminer.Logger { return r }
+
+func TestSamplerDropsBeyondBurst(t *testing.T) {
+	rec := &recordingLogger{}
+	s := NewSampler(rec, time.Minute, 2)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		s.Warn(ctx, "connection failed")
+	}
+
+	if len(rec.msgs) != 2 {
+		t.Errorf("expected 2 messages to pass the burst of 2, got %d", len(rec.msgs))
+	}
+}
+
+func TestSamplerKeysByMinerID(t *testing.T) {
+	rec := &recordingLogger{}
+	s := NewSampler(rec, time.Minute, 1)
+	ctx := context.Background()
+
+	s.Warn(ctx, "connection failed", multiminer.F("miner_id", "a"))
+	s.Warn(ctx, "connection failed", multiminer.F("miner_id", "b"))
+	s.Warn(ctx, "connection failed", multiminer.F("miner_id", "a"))
+
+	if len(rec.msgs) != 2 {
+		t.Errorf("expected one message per distinct miner_id, got %d", len(rec.msgs))
+	}
+}
+
+func TestSamplerWithFieldsSharesWindow(t *testing.T) {
+	rec := &recordingLogger{}
+	s := NewSampler(rec, time.Minute, 1)
+	ctx := context.Background()
+
+	derived := s.WithFields(multiminer.F("endpoint", "10.0.0.1:4028"))
+	derived.Warn(ctx, "connection failed")
+	derived.Warn(ctx, "connection failed")
+
+	if len(rec.msgs) != 1 {
+		t.Errorf("expected derived logger to share the sampling window, got %d messages", len(rec.msgs))
+	}
+}
+
+func TestSamplerDisabledPassesEverything(t *testing.T) {
+	rec := &recordingLogger{}
+	s := NewSampler(rec, time.Minute, 0)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		s.Info(ctx, "poll ok")
+	}
+
+	if len(rec.msgs) != 3 {
+		t.Errorf("expected sampling disabled (burst<=0) to pass everything, got %d", len(rec.msgs))
+	}
+}
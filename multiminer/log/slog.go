@@ -0,0 +1,46 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+// SlogLogger adapts a *slog.Logger to multiminer.Logger.
+type SlogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger wraps l.
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	return &SlogLogger{l: l}
+}
+
+func toSlogArgs(fields []multiminer.Field) []any {
+	args := make([]any, 0, len(fields)*2)
+	for _, f := range fields {
+		args = append(args, f.Key, f.Value)
+	}
+	return args
+}
+
+func (s *SlogLogger) Debug(ctx context.Context, msg string, fields ...multiminer.Field) {
+	s.l.DebugContext(ctx, msg, toSlogArgs(fields)...)
+}
+
+func (s *SlogLogger) Info(ctx context.Context, msg string, fields ...multiminer.Field) {
+	s.l.InfoContext(ctx, msg, toSlogArgs(fields)...)
+}
+
+func (s *SlogLogger) Warn(ctx context.Context, msg string, fields ...multiminer.Field) {
+	s.l.WarnContext(ctx, msg, toSlogArgs(fields)...)
+}
+
+func (s *SlogLogger) Error(ctx context.Context, msg string, fields ...multiminer.Field) {
+	s.l.ErrorContext(ctx, msg, toSlogArgs(fields)...)
+}
+
+func (s *SlogLogger) WithFields(fields ...multiminer.Field) multiminer.Logger {
+	return &SlogLogger{l: s.l.With(toSlogArgs(fields)...)}
+}
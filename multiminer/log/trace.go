@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+// traceLogger decorates inner, adding trace_id/span_id fields pulled out of
+// ctx whenever it carries a valid OpenTelemetry span, so log lines can be
+// correlated with traces without every call site doing it by hand.
+type traceLogger struct {
+	inner multiminer.Logger
+}
+
+// WithTrace wraps inner so every Debug/Info/Warn/Error call is annotated
+// with the trace_id/span_id of the span found in its ctx argument, if any.
+func WithTrace(inner multiminer.Logger) multiminer.Logger {
+	return &traceLogger{inner: inner}
+}
+
+func (t *traceLogger) withSpan(ctx context.Context) multiminer.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return t.inner
+	}
+	return t.inner.WithFields(
+		multiminer.F("trace_id", sc.TraceID().String()),
+		multiminer.F("span_id", sc.SpanID().String()),
+	)
+}
+
+func (t *traceLogger) Debug(ctx context.Context, msg string, fields ...multiminer.Field) {
+	t.withSpan(ctx).Debug(ctx, msg, fields...)
+}
+
+func (t *traceLogger) Info(ctx context.Context, msg string, fields ...multiminer.Field) {
+	t.withSpan(ctx).Info(ctx, msg, fields...)
+}
+
+func (t *traceLogger) Warn(ctx context.Context, msg string, fields ...multiminer.Field) {
+	t.withSpan(ctx).Warn(ctx, msg, fields...)
+}
+
+func (t *traceLogger) Error(ctx context.Context, msg string, fields ...multiminer.Field) {
+	t.withSpan(ctx).Error(ctx, msg, fields...)
+}
+
+func (t *traceLogger) WithFields(fields ...multiminer.Field) multiminer.Logger {
+	return &traceLogger{inner: t.inner.WithFields(fields...)}
+}
@@ -0,0 +1,53 @@
+// Package log adapts multiminer.Logger to popular structured logging
+// backends (zap, zerolog, slog), plus a Sampler for rate-limiting noisy
+// per-device messages and a trace-propagating decorator that pulls
+// trace_id/span_id out of context.Context for every call.
+package log
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+// ZapLogger adapts a *zap.Logger to multiminer.Logger.
+type ZapLogger struct {
+	l *zap.Logger
+}
+
+// NewZapLogger wraps l. Fields passed to Debug/Info/Warn/Error and
+// WithFields are recorded via zap.Any, since multiminer.Field carries an
+// untyped value.
+func NewZapLogger(l *zap.Logger) *ZapLogger {
+	return &ZapLogger{l: l}
+}
+
+func toZapFields(fields []multiminer.Field) []zap.Field {
+	out := make([]zap.Field, len(fields))
+	for i, f := range fields {
+		out[i] = zap.Any(f.Key, f.Value)
+	}
+	return out
+}
+
+func (z *ZapLogger) Debug(ctx context.Context, msg string, fields ...multiminer.Field) {
+	z.l.Debug(msg, toZapFields(fields)...)
+}
+
+func (z *ZapLogger) Info(ctx context.Context, msg string, fields ...multiminer.Field) {
+	z.l.Info(msg, toZapFields(fields)...)
+}
+
+func (z *ZapLogger) Warn(ctx context.Context, msg string, fields ...multiminer.Field) {
+	z.l.Warn(msg, toZapFields(fields)...)
+}
+
+func (z *ZapLogger) Error(ctx context.Context, msg string, fields ...multiminer.Field) {
+	z.l.Error(msg, toZapFields(fields)...)
+}
+
+func (z *ZapLogger) WithFields(fields ...multiminer.Field) multiminer.Logger {
+	return &ZapLogger{l: z.l.With(toZapFields(fields)...)}
+}
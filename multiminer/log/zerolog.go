@@ -0,0 +1,50 @@
+package log
+
+import (
+	"context"
+
+	"github.com/rs/zerolog"
+
+	"github.com/x1unix/go-cgminer-api/multiminer"
+)
+
+// ZerologLogger adapts a zerolog.Logger to multiminer.Logger.
+type ZerologLogger struct {
+	l zerolog.Logger
+}
+
+// NewZerologLogger wraps l.
+func NewZerologLogger(l zerolog.Logger) *ZerologLogger {
+	return &ZerologLogger{l: l}
+}
+
+func applyFields(e *zerolog.Event, fields []multiminer.Field) *zerolog.Event {
+	for _, f := range fields {
+		e = e.Interface(f.Key, f.Value)
+	}
+	return e
+}
+
+func (z *ZerologLogger) Debug(ctx context.Context, msg string, fields ...multiminer.Field) {
+	applyFields(z.l.Debug(), fields).Msg(msg)
+}
+
+func (z *ZerologLogger) Info(ctx context.Context, msg string, fields ...multiminer.Field) {
+	applyFields(z.l.Info(), fields).Msg(msg)
+}
+
+func (z *ZerologLogger) Warn(ctx context.Context, msg string, fields ...multiminer.Field) {
+	applyFields(z.l.Warn(), fields).Msg(msg)
+}
+
+func (z *ZerologLogger) Error(ctx context.Context, msg string, fields ...multiminer.Field) {
+	applyFields(z.l.Error(), fields).Msg(msg)
+}
+
+func (z *ZerologLogger) WithFields(fields ...multiminer.Field) multiminer.Logger {
+	ctx := z.l.With()
+	for _, f := range fields {
+		ctx = ctx.Interface(f.Key, f.Value)
+	}
+	return &ZerologLogger{l: ctx.Logger()}
+}
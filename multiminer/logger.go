@@ -122,6 +122,77 @@ func formatValue(v interface{}) string {
 	}
 }
 
+// logLevelFromString converts a config string log level to LogLevel,
+// defaulting to LogLevelInfo for an empty or unrecognized value.
+func logLevelFromString(level string) LogLevel {
+	switch level {
+	case "debug":
+		return LogLevelDebug
+	case "info":
+		return LogLevelInfo
+	case "warn", "warning":
+		return LogLevelWarn
+	case "error":
+		return LogLevelError
+	default:
+		return LogLevelInfo
+	}
+}
+
+// BuildLogger constructs the Logger described by cfg: a SimpleLogger at the
+// configured level, fanned out to an HTTPLogger when cfg.HTTPTarget is
+// enabled. Callers install the result with SetLogger.
+func BuildLogger(cfg LoggingConfig) Logger {
+	base := NewSimpleLogger(logLevelFromString(cfg.Level))
+	if !cfg.HTTPTarget.Enabled {
+		return base
+	}
+	return newMultiLogger(base, NewHTTPLogger(cfg.HTTPTarget))
+}
+
+// multiLogger fans every call out to each wrapped Logger in order, so e.g.
+// SimpleLogger's stdout output and HTTPLogger's remote shipping can run side
+// by side behind a single Logger.
+type multiLogger struct {
+	loggers []Logger
+}
+
+func newMultiLogger(loggers ...Logger) *multiLogger {
+	return &multiLogger{loggers: loggers}
+}
+
+func (m *multiLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	for _, l := range m.loggers {
+		l.Debug(ctx, msg, fields...)
+	}
+}
+
+func (m *multiLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	for _, l := range m.loggers {
+		l.Info(ctx, msg, fields...)
+	}
+}
+
+func (m *multiLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	for _, l := range m.loggers {
+		l.Warn(ctx, msg, fields...)
+	}
+}
+
+func (m *multiLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	for _, l := range m.loggers {
+		l.Error(ctx, msg, fields...)
+	}
+}
+
+func (m *multiLogger) WithFields(fields ...Field) Logger {
+	next := make([]Logger, len(m.loggers))
+	for i, l := range m.loggers {
+		next[i] = l.WithFields(fields...)
+	}
+	return &multiLogger{loggers: next}
+}
+
 // Global logger instance (optional for library consumers)
 var globalLogger Logger
 
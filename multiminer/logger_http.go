@@ -0,0 +1,274 @@
+package multiminer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LogRecord is the structured payload shipped to an HTTP log target.
+type LogRecord struct {
+	Time     time.Time              `json:"time"`
+	Level    string                 `json:"level"`
+	Message  string                 `json:"message"`
+	MinerID  string                 `json:"miner_id,omitempty"`
+	Endpoint string                 `json:"endpoint,omitempty"`
+	Driver   string                 `json:"driver,omitempty"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+}
+
+// httpTargetIdleTicks is the number of consecutive idle drain cycles a
+// scaled-up worker waits through before it exits and shrinks the pool back down.
+const httpTargetIdleTicks = 5
+
+// HTTPLogger ships LogRecords to a configurable HTTP endpoint. It satisfies
+// Logger so it can be composed with SimpleLogger (e.g. via a multi-logger)
+// or used as the sole global logger.
+//
+// Records are pushed onto a bounded channel and drained by a pool of worker
+// goroutines that batch up to BatchSize records per POST. The worker count
+// scales up when the channel gets hot (>80% full) and scales back down when
+// workers go idle, so a slow receiver never blocks the producer beyond the
+// channel capacity: once it's full, records are dropped and counted.
+//
+// HTTPLogger itself only holds a *httpLoggerCore plus its own fields, so
+// WithFields (called per-session by drivers to attach miner_id/address) can
+// return a derived logger that shares the same channel, worker pool, and
+// drop counter instead of spinning up a second, independently-scaled pool
+// behind the same cfg.MaxWorkers budget.
+type HTTPLogger struct {
+	core   *httpLoggerCore
+	fields []Field
+}
+
+// httpLoggerCore is the state shared by an HTTPLogger and every logger
+// derived from it via WithFields.
+type httpLoggerCore struct {
+	cfg    HTTPTarget
+	client *http.Client
+
+	logCh   chan LogRecord
+	workers atomic.Int32
+
+	dropped atomic.Int64
+	wg      sync.WaitGroup
+}
+
+// NewHTTPLogger creates an HTTPLogger shipping records to cfg.URL.
+// It starts one worker immediately; additional workers are spawned on
+// demand as the queue fills, up to cfg.MaxWorkers.
+func NewHTTPLogger(cfg HTTPTarget) *HTTPLogger {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxWorkers <= 0 {
+		cfg.MaxWorkers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+
+	core := &httpLoggerCore{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logCh:  make(chan LogRecord, cfg.QueueSize),
+	}
+	core.spawnWorker()
+	return &HTTPLogger{core: core}
+}
+
+func (l *HTTPLogger) Debug(ctx context.Context, msg string, fields ...Field) {
+	l.log("DEBUG", msg, fields...)
+}
+func (l *HTTPLogger) Info(ctx context.Context, msg string, fields ...Field) {
+	l.log("INFO", msg, fields...)
+}
+func (l *HTTPLogger) Warn(ctx context.Context, msg string, fields ...Field) {
+	l.log("WARN", msg, fields...)
+}
+func (l *HTTPLogger) Error(ctx context.Context, msg string, fields ...Field) {
+	l.log("ERROR", msg, fields...)
+}
+
+func (l *HTTPLogger) WithFields(fields ...Field) Logger {
+	newFields := make([]Field, 0, len(l.fields)+len(fields))
+	newFields = append(newFields, l.fields...)
+	newFields = append(newFields, fields...)
+	return &HTTPLogger{core: l.core, fields: newFields}
+}
+
+// DroppedCount returns the number of records dropped because the queue was full.
+func (l *HTTPLogger) DroppedCount() int64 { return l.core.dropped.Load() }
+
+// Close stops accepting new records and waits for in-flight workers to
+// drain. Since WithFields-derived loggers share the same core, Close must
+// only be called once the core logger and every session-scoped logger
+// derived from it are done with it.
+func (l *HTTPLogger) Close() {
+	close(l.core.logCh)
+	l.core.wg.Wait()
+}
+
+func (l *HTTPLogger) log(level, msg string, fields ...Field) {
+	rec := LogRecord{Time: time.Now(), Level: level, Message: msg}
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	if len(all) > 0 {
+		rec.Fields = make(map[string]interface{}, len(all))
+		for _, f := range all {
+			switch f.Key {
+			case "miner_id":
+				rec.MinerID = formatValue(f.Value)
+			case "address", "endpoint":
+				rec.Endpoint = formatValue(f.Value)
+			case "driver":
+				rec.Driver = formatValue(f.Value)
+			}
+			rec.Fields[f.Key] = f.Value
+		}
+	}
+
+	select {
+	case l.core.logCh <- rec:
+	default:
+		l.core.dropped.Add(1)
+		return
+	}
+
+	// Scale up when the queue is hot and we have headroom.
+	if l.core.shouldScaleUp() {
+		l.core.spawnWorker()
+	}
+}
+
+func (c *httpLoggerCore) shouldScaleUp() bool {
+	if int(c.workers.Load()) >= c.cfg.MaxWorkers {
+		return false
+	}
+	return len(c.logCh) > (cap(c.logCh)*8)/10
+}
+
+// spawnWorker atomically reserves a worker slot and starts draining logCh.
+// It's a no-op if MaxWorkers has already been reached by a concurrent caller.
+func (c *httpLoggerCore) spawnWorker() {
+	for {
+		cur := c.workers.Load()
+		if int(cur) >= c.cfg.MaxWorkers {
+			return
+		}
+		if c.workers.CompareAndSwap(cur, cur+1) {
+			break
+		}
+	}
+
+	c.wg.Add(1)
+	go c.runWorker()
+}
+
+func (c *httpLoggerCore) runWorker() {
+	defer c.wg.Done()
+	defer c.workers.Add(-1)
+
+	idleTicks := 0
+	backoff := time.Second
+
+	for {
+		batch, closed := c.drainBatch()
+		if len(batch) > 0 {
+			idleTicks = 0
+			if err := c.post(batch); err != nil {
+				time.Sleep(backoff)
+				if backoff < 30*time.Second {
+					backoff *= 2
+				}
+			} else {
+				backoff = time.Second
+			}
+		}
+
+		if closed {
+			// logCh is closed and drained: every worker exits, including the
+			// last one, so Close()'s wg.Wait() can return.
+			return
+		}
+		if len(batch) == 0 {
+			idleTicks++
+			if idleTicks >= httpTargetIdleTicks && c.workers.Load() > 1 {
+				return
+			}
+		}
+	}
+}
+
+// drainBatch waits up to 100ms for the first record, then greedily drains up
+// to BatchSize-1 more without blocking so a slow receiver doesn't cause a hot
+// spin loop. It returns closed=true once logCh is closed and fully drained,
+// which callers must treat as an unconditional exit signal. A nil batch with
+// closed=false means the wait timed out with nothing to send, which is how
+// idle workers notice they can scale back down.
+func (c *httpLoggerCore) drainBatch() (batch []LogRecord, closed bool) {
+	select {
+	case rec, ok := <-c.logCh:
+		if !ok {
+			return nil, true
+		}
+		batch = make([]LogRecord, 0, c.cfg.BatchSize)
+		batch = append(batch, rec)
+	case <-time.After(100 * time.Millisecond):
+		return nil, false
+	}
+
+	for len(batch) < c.cfg.BatchSize {
+		select {
+		case rec, ok := <-c.logCh:
+			if !ok {
+				return batch, true
+			}
+			batch = append(batch, rec)
+		default:
+			return batch, false
+		}
+	}
+	return batch, false
+}
+
+func (c *httpLoggerCore) post(batch []LogRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range batch {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.cfg.URL, bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	for k, v := range c.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	if c.cfg.AuthBearer != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.AuthBearer)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return NewDeviceError("http log target rejected batch", resp.Status, nil)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package multiminer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHTTPLoggerPostsRecords(t *testing.T) {
+	var mu sync.Mutex
+	var received []LogRecord
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dec := json.NewDecoder(r.Body)
+		for {
+			var rec LogRecord
+			if err := dec.Decode(&rec); err != nil {
+				break
+			}
+			mu.Lock()
+			received = append(received, rec)
+			mu.Unlock()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	l := NewHTTPLogger(HTTPTarget{Enabled: true, URL: srv.URL, BatchSize: 10})
+	l.Info(context.Background(), "hello", F("miner_id", "rig-1"))
+	l.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("expected 1 record posted, got %d", len(received))
+	}
+	if received[0].Message != "hello" || received[0].MinerID != "rig-1" {
+		t.Errorf("unexpected record: %+v", received[0])
+	}
+}
+
+// TestHTTPLoggerCloseDoesNotDeadlock guards against regressing the bug where
+// the sole base worker could never observe itself as exitable and Close
+// would hang forever.
+func TestHTTPLoggerCloseDoesNotDeadlock(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	l := NewHTTPLogger(HTTPTarget{Enabled: true, URL: srv.URL})
+
+	done := make(chan struct{})
+	go func() {
+		l.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() deadlocked")
+	}
+}
+
+func TestHTTPLoggerDropsWhenQueueFull(t *testing.T) {
+	block := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	l := NewHTTPLogger(HTTPTarget{Enabled: true, URL: srv.URL, QueueSize: 1, MaxWorkers: 1, BatchSize: 1})
+	for i := 0; i < 10; i++ {
+		l.Info(context.Background(), "spam")
+	}
+	close(block)
+	l.Close()
+
+	if l.DroppedCount() == 0 {
+		t.Fatal("expected some records to be dropped once the queue filled")
+	}
+}
+
+// TestHTTPLoggerWithFieldsSharesWorkerPool guards against regressing the bug
+// where each session's WithFields-derived logger got its own zero-valued
+// worker/dropped/wg state on the shared channel, letting the effective
+// worker count grow past cfg.MaxWorkers and letting derived workers outlive
+// Close() since their own counters never reached zero.
+func TestHTTPLoggerWithFieldsSharesWorkerPool(t *testing.T) {
+	l := NewHTTPLogger(HTTPTarget{MaxWorkers: 4})
+	derived := l.WithFields(F("miner_id", "rig-1")).(*HTTPLogger)
+
+	if derived.core != l.core {
+		t.Fatal("expected WithFields to share the base logger's core")
+	}
+
+	derived.Info(context.Background(), "hot")
+	if l.core.workers.Load() > int32(l.core.cfg.MaxWorkers) {
+		t.Fatalf("worker count %d exceeds MaxWorkers %d", l.core.workers.Load(), l.core.cfg.MaxWorkers)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		l.Close()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close() deadlocked with a WithFields-derived logger outstanding")
+	}
+}
+
+func TestBuildLoggerWiresHTTPTarget(t *testing.T) {
+	var mu sync.Mutex
+	posted := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		posted++
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	cfg := LoggingConfig{
+		Level:      "debug",
+		HTTPTarget: HTTPTarget{Enabled: true, URL: srv.URL, BatchSize: 1},
+	}
+	logger := BuildLogger(cfg)
+	if _, ok := logger.(*multiLogger); !ok {
+		t.Fatalf("expected BuildLogger to return a *multiLogger when HTTPTarget is enabled, got %T", logger)
+	}
+
+	logger.Info(context.Background(), "wired")
+
+	if ml, ok := logger.(*multiLogger); ok {
+		for _, l := range ml.loggers {
+			if httpL, ok := l.(*HTTPLogger); ok {
+				httpL.Close()
+			}
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if posted == 0 {
+		t.Fatal("expected BuildLogger's HTTPLogger to ship the log record")
+	}
+}
+
+func TestBuildLoggerWithoutHTTPTarget(t *testing.T) {
+	logger := BuildLogger(LoggingConfig{Level: "warn"})
+	if _, ok := logger.(*SimpleLogger); !ok {
+		t.Fatalf("expected a plain *SimpleLogger when HTTPTarget is disabled, got %T", logger)
+	}
+}
@@ -16,11 +16,22 @@ type Device struct {
 
 // Manager tracks devices and provides operations across them.
 type Manager struct {
-	reg  *Registry
-	mu   sync.RWMutex
-	dev  map[MinerID]*Device
-	opt  ManagerOptions
-	pool *ConnectionPool
+	reg      *Registry
+	mu       sync.RWMutex
+	dev      map[MinerID]*Device
+	opt      ManagerOptions
+	pool     *ConnectionPool
+	store    PersistentStore
+	groupsMu sync.RWMutex
+	groups   map[string]*Group
+
+	condMu     sync.RWMutex
+	conditions map[MinerID][]Condition
+	condSubsMu sync.RWMutex
+	condSubs   map[chan ConditionEvent]struct{}
+
+	restartMu   sync.RWMutex
+	restartedAt map[MinerID]time.Time
 }
 
 func NewManager(reg *Registry) *Manager { return NewManagerWithOptions(reg, defaultOptions()) }
@@ -28,11 +39,58 @@ func NewManagerWithOptions(reg *Registry, opt ManagerOptions) *Manager {
 	pool := NewConnectionPool()
 	pool.SetLimits(5, 10, 5*time.Minute)
 
-	return &Manager{
-		reg:  reg,
-		dev:  make(map[MinerID]*Device),
-		opt:  opt,
-		pool: pool,
+	m := &Manager{
+		reg:         reg,
+		dev:         make(map[MinerID]*Device),
+		opt:         opt,
+		pool:        pool,
+		store:       opt.Store,
+		groups:      make(map[string]*Group),
+		conditions:  make(map[MinerID][]Condition),
+		condSubs:    make(map[chan ConditionEvent]struct{}),
+		restartedAt: make(map[MinerID]time.Time),
+	}
+	pool.SetOnBreakerChange(m.setCondition)
+
+	if m.store != nil {
+		m.loadFromStore()
+		m.loadGroupsFromStore()
+	}
+
+	return m
+}
+
+// loadFromStore replays persisted device records on startup. Entries whose
+// driver can't be found by name are skipped; with RevalidateOnStart set, each
+// entry is re-detected and evicted (from both the map and the store) if its
+// driver no longer matches.
+func (m *Manager) loadFromStore() {
+	records, err := m.store.List()
+	if err != nil {
+		LogWarn(context.Background(), "failed to load persisted devices", F("error", err))
+		return
+	}
+
+	ctx := context.Background()
+	for _, rec := range records {
+		d := m.reg.Get(rec.DriverName)
+		if d == nil {
+			LogWarn(ctx, "dropping persisted device with unknown driver",
+				F("id", string(rec.ID)), F("driver", rec.DriverName))
+			continue
+		}
+
+		if m.opt.RevalidateOnStart {
+			ok, err := d.Detect(ctx, Endpoint{Address: rec.Address})
+			if err != nil || !ok {
+				LogWarn(ctx, "evicting persisted device that failed revalidation",
+					F("id", string(rec.ID)), F("driver", rec.DriverName))
+				_ = m.store.Delete(rec.ID)
+				continue
+			}
+		}
+
+		m.dev[rec.ID] = &Device{ID: rec.ID, Endpoint: Endpoint{Address: rec.Address}, Driver: d, DriverName: rec.DriverName}
 	}
 }
 
@@ -44,11 +102,39 @@ func (m *Manager) AddOrDetect(ctx context.Context, id MinerID, ep Endpoint, d Dr
 		var err error
 		d, err = m.reg.Detect(ctx, ep)
 		if err != nil {
+			m.setCondition(id, Condition{
+				Type: DeviceReady, Status: ConditionFalse, Severity: SeverityError,
+				Reason: ReasonDeviceNotFound, Message: err.Error(),
+			})
 			return err
 		}
 	}
 	name := d.Name()
 	m.dev[id] = &Device{ID: id, Endpoint: ep, Driver: d, DriverName: name}
+	m.setCondition(id, Condition{
+		Type: DeviceReady, Status: ConditionTrue, Severity: SeverityInfo,
+		Reason: ReasonDetected, Message: "driver " + name + " detected",
+	})
+
+	if m.store != nil {
+		rec := DeviceRecord{ID: id, Address: ep.Address, DriverName: name, DetectedAt: time.Now()}
+		if err := m.store.Put(rec); err != nil {
+			LogWarn(ctx, "failed to persist device", F("id", string(id)), F("error", err))
+		}
+	}
+
+	return nil
+}
+
+// Forget removes a device from the manager and, if persistence is enabled, from the store.
+func (m *Manager) Forget(id MinerID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.dev, id)
+
+	if m.store != nil {
+		return m.store.Delete(id)
+	}
 	return nil
 }
 
@@ -73,13 +159,24 @@ func (m *Manager) WithSession(ctx context.Context, id MinerID, fn func(Session)
 
 	sess, err := m.pool.GetSession(ctx, id, d)
 	if err != nil {
+		m.setCondition(id, Condition{
+			Type: DeviceReachable, Status: ConditionFalse, Severity: SeverityWarning,
+			Reason: ReasonSessionOpenFailed, Message: err.Error(),
+		})
 		return err
 	}
-
-	// Ensure session is returned to pool
-	defer m.pool.ReturnSession(id, sess)
-
-	return fn(sess)
+	m.setCondition(id, Condition{
+		Type: DeviceReachable, Status: ConditionTrue, Severity: SeverityInfo, Reason: ReasonSessionOK,
+	})
+
+	// Ensure session is returned to pool, passing along fn's outcome so the
+	// circuit breaker can react to failures discovered mid-operation, not
+	// just at connect time.
+	var runErr error
+	defer func() { m.pool.ReturnSession(id, sess, runErr) }()
+
+	runErr = fn(sess)
+	return runErr
 }
 
 // DeviceInfo is a safe DTO for API responses.
@@ -102,6 +199,9 @@ func (m *Manager) DeviceInfos() []DeviceInfo {
 // Close gracefully shuts down the manager and connection pool
 func (m *Manager) Close() error {
 	m.pool.Close()
+	if m.store != nil {
+		return m.store.Close()
+	}
 	return nil
 }
 
@@ -125,3 +225,16 @@ func (m *Manager) StartCleanup(ctx context.Context, interval time.Duration) {
 func (m *Manager) GetPoolStats() map[MinerID]PoolStats {
 	return m.pool.Stats()
 }
+
+// SetOnSessionOpen registers fn to be called whenever the connection pool
+// opens a new session to a device. See ConnectionPool.SetOnSessionOpen.
+func (m *Manager) SetOnSessionOpen(fn func()) {
+	m.pool.SetOnSessionOpen(fn)
+}
+
+// ResetBreaker clears id's circuit breaker, allowing connections to be
+// attempted again immediately regardless of prior failures. Intended for an
+// operator to call once the underlying problem has been fixed.
+func (m *Manager) ResetBreaker(id MinerID) {
+	m.pool.Reset(id)
+}
@@ -27,52 +27,73 @@ func (d *mockDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
 	return &mockSession{}, nil
 }
 
+// unhealthyDriver implements SessionHealthChecker and always opens sessions
+// that fail their health check, for exercising DevicePool.healthCheckAndWarm.
+type unhealthyDriver struct {
+	mockDriver
+	checked int
+	opened  int
+}
+
+func (d *unhealthyDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
+	d.opened++
+	return &mockSession{}, nil
+}
+
+func (d *unhealthyDriver) HealthCheck(ctx context.Context, sess Session) error {
+	d.checked++
+	return NewDeviceError("health check failed", "simulated dead session", nil)
+}
+
 // mockSession for testing
 type mockSession struct{}
 
-func (s *mockSession) Close() error                                                   { return nil }
-func (s *mockSession) Model(ctx context.Context) (Model, error)                      { return Model{Vendor: "Mock"}, nil }
-func (s *mockSession) Stats(ctx context.Context) (Stats, error)                      { return Stats{}, nil }
-func (s *mockSession) Summary(ctx context.Context) (Summary, error)                  { return Summary{}, nil }
-func (s *mockSession) Pools(ctx context.Context) ([]Pool, error)                     { return nil, nil }
-func (s *mockSession) AddPool(ctx context.Context, url, user, pass string) error     { return nil }
-func (s *mockSession) EnablePool(ctx context.Context, poolID int64) error            { return nil }
-func (s *mockSession) DisablePool(ctx context.Context, poolID int64) error           { return nil }
-func (s *mockSession) RemovePool(ctx context.Context, poolID int64) error            { return nil }
-func (s *mockSession) SwitchPool(ctx context.Context, poolID int64) error            { return nil }
-func (s *mockSession) Restart(ctx context.Context) error                             { return nil }
-func (s *mockSession) Quit(ctx context.Context) error                                { return nil }
+func (s *mockSession) Close() error                                              { return nil }
+func (s *mockSession) Model(ctx context.Context) (Model, error)                  { return Model{Vendor: "Mock"}, nil }
+func (s *mockSession) Stats(ctx context.Context) (Stats, error)                  { return Stats{}, nil }
+func (s *mockSession) Summary(ctx context.Context) (Summary, error)              { return Summary{}, nil }
+func (s *mockSession) Pools(ctx context.Context) ([]Pool, error)                 { return nil, nil }
+func (s *mockSession) AddPool(ctx context.Context, url, user, pass string) error { return nil }
+func (s *mockSession) EnablePool(ctx context.Context, poolID int64) error        { return nil }
+func (s *mockSession) DisablePool(ctx context.Context, poolID int64) error       { return nil }
+func (s *mockSession) RemovePool(ctx context.Context, poolID int64) error        { return nil }
+func (s *mockSession) SwitchPool(ctx context.Context, poolID int64) error        { return nil }
+func (s *mockSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	return nil
+}
+func (s *mockSession) Restart(ctx context.Context) error { return nil }
+func (s *mockSession) Quit(ctx context.Context) error    { return nil }
 func (s *mockSession) Exec(ctx context.Context, command string, parameter string) ([]byte, error) {
 	return []byte("{}"), nil
 }
-func (s *mockSession) GetPowerMode(ctx context.Context) (PowerMode, error) { return PowerMode{}, nil }
+func (s *mockSession) GetPowerMode(ctx context.Context) (PowerMode, error)    { return PowerMode{}, nil }
 func (s *mockSession) SetPowerMode(ctx context.Context, mode PowerMode) error { return nil }
-func (s *mockSession) GetFan(ctx context.Context) (FanConfig, error)         { return FanConfig{}, nil }
-func (s *mockSession) SetFan(ctx context.Context, fan FanConfig) error       { return nil }
+func (s *mockSession) GetFan(ctx context.Context) (FanConfig, error)          { return FanConfig{}, nil }
+func (s *mockSession) SetFan(ctx context.Context, fan FanConfig) error        { return nil }
 
 func TestManagerAddDevice(t *testing.T) {
 	reg := NewRegistry()
 	driver := &mockDriver{name: "test-driver", shouldDetect: true}
 	reg.Register(driver)
-	
+
 	mgr := NewManager(reg)
 	defer mgr.Close()
-	
+
 	ctx := context.Background()
 	id := MinerID("test-device")
 	ep := Endpoint{Address: "192.168.1.100:4028"}
-	
+
 	// Test adding device with specific driver
 	err := mgr.AddOrDetect(ctx, id, ep, driver)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	devices := mgr.List()
 	if len(devices) != 1 {
 		t.Errorf("Expected 1 device, got %d", len(devices))
 	}
-	
+
 	if devices[0].ID != id {
 		t.Errorf("Expected device ID %s, got %s", id, devices[0].ID)
 	}
@@ -82,25 +103,25 @@ func TestManagerAutoDetect(t *testing.T) {
 	reg := NewRegistry()
 	driver := &mockDriver{name: "auto-driver", shouldDetect: true}
 	reg.Register(driver)
-	
+
 	mgr := NewManager(reg)
 	defer mgr.Close()
-	
+
 	ctx := context.Background()
 	id := MinerID("auto-device")
 	ep := Endpoint{Address: "192.168.1.101:4028"}
-	
+
 	// Test auto-detection
 	err := mgr.AddOrDetect(ctx, id, ep, nil)
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
-	
+
 	devices := mgr.List()
 	if len(devices) != 1 {
 		t.Errorf("Expected 1 device, got %d", len(devices))
 	}
-	
+
 	if devices[0].DriverName != "auto-driver" {
 		t.Errorf("Expected driver name %s, got %s", "auto-driver", devices[0].DriverName)
 	}
@@ -110,20 +131,20 @@ func TestManagerWithSession(t *testing.T) {
 	reg := NewRegistry()
 	driver := &mockDriver{name: "session-driver", shouldDetect: true}
 	reg.Register(driver)
-	
+
 	mgr := NewManager(reg)
 	defer mgr.Close()
-	
+
 	ctx := context.Background()
 	id := MinerID("session-device")
 	ep := Endpoint{Address: "192.168.1.102:4028"}
-	
+
 	// Add device
 	err := mgr.AddOrDetect(ctx, id, ep, driver)
 	if err != nil {
 		t.Errorf("Expected no error adding device, got %v", err)
 	}
-	
+
 	// Test session usage
 	sessionUsed := false
 	err = mgr.WithSession(ctx, id, func(sess Session) error {
@@ -131,11 +152,11 @@ func TestManagerWithSession(t *testing.T) {
 		_, err := sess.Model(ctx)
 		return err
 	})
-	
+
 	if err != nil {
 		t.Errorf("Expected no error in session, got %v", err)
 	}
-	
+
 	if !sessionUsed {
 		t.Error("Session callback was not called")
 	}
@@ -145,14 +166,14 @@ func TestManagerDeviceNotFound(t *testing.T) {
 	reg := NewRegistry()
 	mgr := NewManager(reg)
 	defer mgr.Close()
-	
+
 	ctx := context.Background()
 	id := MinerID("nonexistent")
-	
+
 	err := mgr.WithSession(ctx, id, func(sess Session) error {
 		return nil
 	})
-	
+
 	if err != ErrNotFound {
 		t.Errorf("Expected ErrNotFound, got %v", err)
 	}
@@ -161,9 +182,9 @@ func TestManagerDeviceNotFound(t *testing.T) {
 func TestConnectionPool(t *testing.T) {
 	pool := NewConnectionPool()
 	defer pool.Close()
-	
+
 	pool.SetLimits(2, 5, time.Minute)
-	
+
 	// Create mock device
 	driver := &mockDriver{name: "pool-driver", shouldDetect: true}
 	device := &Device{
@@ -172,28 +193,28 @@ func TestConnectionPool(t *testing.T) {
 		Endpoint:   Endpoint{Address: "192.168.1.103:4028"},
 		DriverName: "pool-driver",
 	}
-	
+
 	ctx := context.Background()
-	
+
 	// Get session from pool
 	sess1, err := pool.GetSession(ctx, device.ID, device)
 	if err != nil {
 		t.Errorf("Expected no error getting session, got %v", err)
 	}
-	
+
 	// Return session to pool
-	pool.ReturnSession(device.ID, sess1)
-	
+	pool.ReturnSession(device.ID, sess1, nil)
+
 	// Get session again (should reuse from pool)
 	sess2, err := pool.GetSession(ctx, device.ID, device)
 	if err != nil {
 		t.Errorf("Expected no error getting session from pool, got %v", err)
 	}
-	
+
 	if sess1 != sess2 {
 		t.Error("Expected to reuse session from pool")
 	}
-	
+
 	// Check pool stats
 	stats := pool.Stats()
 	if deviceStats, exists := stats[device.ID]; exists {
@@ -203,4 +224,254 @@ func TestConnectionPool(t *testing.T) {
 	} else {
 		t.Error("Expected device stats to exist")
 	}
-}
\ No newline at end of file
+}
+
+func TestConnectionPoolCircuitBreakerOpensAndRecovers(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+
+	pool.SetBreakerLimits(2, 10*time.Millisecond, 50*time.Millisecond)
+
+	driver := &mockDriver{name: "breaker-driver", shouldDetect: true}
+	device := &Device{
+		ID:         MinerID("breaker-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.104:4028"},
+		DriverName: "breaker-driver",
+	}
+
+	ctx := context.Background()
+
+	// Two consecutive transient failures should trip the breaker open.
+	for i := 0; i < 2; i++ {
+		sess, err := pool.GetSession(ctx, device.ID, device)
+		if err != nil {
+			t.Fatalf("unexpected error getting session: %v", err)
+		}
+		pool.ReturnSession(device.ID, sess, NewTransientError("boom", "simulated failure", nil))
+	}
+
+	if _, err := pool.GetSession(ctx, device.ID, device); err == nil {
+		t.Fatal("expected breaker to be open after consecutive transient failures")
+	}
+
+	// Wait out the backoff: the next attempt should be let through as a
+	// half-open probe.
+	time.Sleep(15 * time.Millisecond)
+	sess, err := pool.GetSession(ctx, device.ID, device)
+	if err != nil {
+		t.Fatalf("expected half-open probe to be allowed through, got %v", err)
+	}
+
+	// A second, concurrent attempt must be rejected while the probe is in flight.
+	if _, err := pool.GetSession(ctx, device.ID, device); err == nil {
+		t.Error("expected concurrent half-open probe to be rejected")
+	}
+
+	// The probe succeeding should close the breaker again.
+	pool.ReturnSession(device.ID, sess, nil)
+
+	if _, err := pool.GetSession(ctx, device.ID, device); err != nil {
+		t.Errorf("expected breaker closed after successful probe, got %v", err)
+	}
+}
+
+func TestConnectionPoolCircuitBreakerPermanentTripRequiresReset(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+
+	driver := &mockDriver{name: "perm-driver", shouldDetect: true}
+	device := &Device{
+		ID:         MinerID("perm-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.105:4028"},
+		DriverName: "perm-driver",
+	}
+
+	ctx := context.Background()
+
+	sess, err := pool.GetSession(ctx, device.ID, device)
+	if err != nil {
+		t.Fatalf("unexpected error getting session: %v", err)
+	}
+	pool.ReturnSession(device.ID, sess, NewPermanentError("not implemented", "driver refuses", nil))
+
+	if _, err := pool.GetSession(ctx, device.ID, device); err == nil || !IsPermanent(err) {
+		t.Fatalf("expected permanent breaker trip, got %v", err)
+	}
+
+	pool.Reset(device.ID)
+
+	if _, err := pool.GetSession(ctx, device.ID, device); err != nil {
+		t.Errorf("expected breaker closed after Reset, got %v", err)
+	}
+}
+
+func TestConnectionPoolHealthCheckDiscardsDeadSessionsAndPrewarms(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+	pool.SetMinIdle(2)
+
+	driver := &unhealthyDriver{mockDriver: mockDriver{name: "unhealthy-driver", shouldDetect: true}}
+	device := &Device{
+		ID:         MinerID("health-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.106:4028"},
+		DriverName: "unhealthy-driver",
+	}
+
+	ctx := context.Background()
+
+	sess, err := pool.GetSession(ctx, device.ID, device)
+	if err != nil {
+		t.Fatalf("unexpected error getting session: %v", err)
+	}
+	pool.ReturnSession(device.ID, sess, nil)
+
+	if driver.opened != 1 {
+		t.Fatalf("expected 1 session opened before health check pass, got %d", driver.opened)
+	}
+
+	pool.runHealthCheckPass(ctx)
+
+	if driver.checked != 1 {
+		t.Errorf("expected the idle session to be health-checked once, got %d", driver.checked)
+	}
+	// The dead session is discarded and minIdle (2) warm replacements are opened.
+	if driver.opened != 3 {
+		t.Errorf("expected 2 pre-warmed replacements on top of the original open, got %d total opens", driver.opened)
+	}
+
+	stats := pool.Stats()[device.ID]
+	if stats.IdleConnections != 2 {
+		t.Errorf("expected 2 idle connections after pre-warming, got %d", stats.IdleConnections)
+	}
+}
+
+func TestConnectionPoolWaitStats(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+
+	driver := &mockDriver{name: "wait-driver", shouldDetect: true}
+	device := &Device{
+		ID:         MinerID("wait-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.107:4028"},
+		DriverName: "wait-driver",
+	}
+
+	ctx := context.Background()
+	sess, err := pool.GetSession(ctx, device.ID, device)
+	if err != nil {
+		t.Fatalf("unexpected error getting session: %v", err)
+	}
+	pool.ReturnSession(device.ID, sess, nil)
+
+	stats := pool.Stats()[device.ID]
+	if stats.WaitCount != 1 {
+		t.Errorf("expected 1 recorded wait sample, got %d", stats.WaitCount)
+	}
+}
+
+func TestConnectionPoolQueuesWaitersAtMaxOpen(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+	pool.SetLimits(1, 1, time.Minute)
+
+	driver := &mockDriver{name: "queue-driver", shouldDetect: true}
+	device := &Device{
+		ID:         MinerID("queue-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.108:4028"},
+		DriverName: "queue-driver",
+	}
+
+	ctx := context.Background()
+	first, err := pool.GetSession(ctx, device.ID, device)
+	if err != nil {
+		t.Fatalf("unexpected error getting first session: %v", err)
+	}
+
+	got := make(chan Session, 1)
+	go func() {
+		sess, err := pool.GetSession(ctx, device.ID, device)
+		if err != nil {
+			t.Errorf("unexpected error from queued GetSession: %v", err)
+			return
+		}
+		got <- sess
+	}()
+
+	select {
+	case <-got:
+		t.Fatal("second GetSession returned before the pool had any room")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	pool.ReturnSession(device.ID, first, nil)
+
+	select {
+	case sess := <-got:
+		if sess != first {
+			t.Errorf("expected the queued waiter to receive the returned session")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("queued GetSession never returned after a session was freed")
+	}
+}
+
+func TestConnectionPoolGetSessionTimesOutWaitingWithDeadline(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+	pool.SetLimits(1, 1, time.Minute)
+
+	driver := &mockDriver{name: "timeout-driver", shouldDetect: true}
+	device := &Device{
+		ID:         MinerID("timeout-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.109:4028"},
+		DriverName: "timeout-driver",
+	}
+
+	bg := context.Background()
+	if _, err := pool.GetSession(bg, device.ID, device); err != nil {
+		t.Fatalf("unexpected error getting session: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(bg, 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := pool.GetSession(ctx, device.ID, device); err == nil {
+		t.Fatal("expected GetSession to time out waiting for a free session")
+	}
+}
+
+func TestConnectionPoolRateLimiterRejects(t *testing.T) {
+	pool := NewConnectionPool()
+	defer pool.Close()
+	pool.SetRateLimit(1, 1)
+
+	driver := &mockDriver{name: "limiter-driver", shouldDetect: true}
+	device := &Device{
+		ID:         MinerID("limiter-test"),
+		Driver:     driver,
+		Endpoint:   Endpoint{Address: "192.168.1.110:4028"},
+		DriverName: "limiter-driver",
+	}
+
+	ctx := context.Background()
+	sess, err := pool.GetSession(ctx, device.ID, device)
+	if err != nil {
+		t.Fatalf("unexpected error getting first session: %v", err)
+	}
+	pool.ReturnSession(device.ID, sess, nil)
+
+	if _, err := pool.GetSession(ctx, device.ID, device); err == nil {
+		t.Fatal("expected the second GetSession to be rejected by the rate limiter")
+	}
+
+	stats := pool.Stats()[device.ID]
+	if stats.RejectedDueToLimiter != 1 {
+		t.Errorf("expected 1 rejection recorded, got %d", stats.RejectedDueToLimiter)
+	}
+}
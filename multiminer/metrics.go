@@ -0,0 +1,171 @@
+package multiminer
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// defaultScrapeTTL bounds how often per-device Stats/Summary are refreshed,
+// so a Prometheus scrape every 15s doesn't turn into a hammering of every rig.
+const defaultScrapeTTL = 15 * time.Second
+
+// minerSnapshot is the cached per-device data a scrape reads from.
+type minerSnapshot struct {
+	at      time.Time
+	stats   Stats
+	summary Summary
+	pool    string // active pool URL, best-effort
+}
+
+// Metrics is a prometheus.Collector that exposes per-miner gauges alongside
+// process counters for the REST API, refreshed lazily with a scrape TTL so
+// concurrent scrapes share one device poll.
+type Metrics struct {
+	mgr       *Manager
+	scrapeTTL time.Duration
+	registry  *prometheus.Registry
+
+	mu    sync.Mutex
+	cache map[MinerID]minerSnapshot
+
+	hashrate5sDesc *prometheus.Desc
+	hashrateAvDesc *prometheus.Desc
+	tempMaxDesc    *prometheus.Desc
+	acceptedDesc   *prometheus.Desc
+	rejectedDesc   *prometheus.Desc
+
+	ExecTotal           *prometheus.CounterVec
+	ValidatorRejected   *prometheus.CounterVec
+	SessionOpenTotal    prometheus.Counter
+	HTTPRequestDuration *prometheus.HistogramVec
+}
+
+// NewMetrics creates a Metrics collector for mgr. scrapeTTL <= 0 uses
+// defaultScrapeTTL.
+func NewMetrics(mgr *Manager, scrapeTTL time.Duration) *Metrics {
+	if scrapeTTL <= 0 {
+		scrapeTTL = defaultScrapeTTL
+	}
+	labels := []string{"miner_id", "driver", "pool"}
+
+	m := &Metrics{
+		mgr:       mgr,
+		scrapeTTL: scrapeTTL,
+		registry:  prometheus.NewRegistry(),
+		cache:     make(map[MinerID]minerSnapshot),
+
+		hashrate5sDesc: prometheus.NewDesc("multiminer_hashrate_5s_ghs", "5s-window hashrate in GH/s", labels, nil),
+		hashrateAvDesc: prometheus.NewDesc("multiminer_hashrate_avg_ghs", "Average hashrate in GH/s", labels, nil),
+		tempMaxDesc:    prometheus.NewDesc("multiminer_temp_max_celsius", "Maximum reported board temperature", labels, nil),
+		acceptedDesc:   prometheus.NewDesc("multiminer_shares_accepted_total", "Accepted shares", labels, nil),
+		rejectedDesc:   prometheus.NewDesc("multiminer_shares_rejected_total", "Rejected shares", labels, nil),
+
+		ExecTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "multiminer_exec_total",
+			Help: "Raw commands executed via the REST API, by command and result.",
+		}, []string{"command", "result"}),
+		ValidatorRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "multiminer_validator_rejected_total",
+			Help: "Requests rejected by input validation, by validator kind.",
+		}, []string{"kind"}),
+		SessionOpenTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "multiminer_session_open_total",
+			Help: "Device sessions opened through the connection pool.",
+		}),
+		HTTPRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "multiminer_http_request_duration_seconds",
+			Help:    "REST API request latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "path", "status"}),
+	}
+
+	m.registry.MustRegister(m, m.ExecTotal, m.ValidatorRejected, m.SessionOpenTotal, m.HTTPRequestDuration)
+	mgr.SetOnSessionOpen(m.SessionOpenTotal.Inc)
+	return m
+}
+
+// Handler returns the http.Handler that serves this collector's registry in
+// the Prometheus text exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.hashrate5sDesc
+	ch <- m.hashrateAvDesc
+	ch <- m.tempMaxDesc
+	ch <- m.acceptedDesc
+	ch <- m.rejectedDesc
+}
+
+// Collect implements prometheus.Collector, reading each device's cached (or
+// freshly-refreshed) snapshot.
+//
+// NOTE: fan RPM isn't exposed here — the Session interface only reports fan
+// mode/speed percent (GetFan), not a tachometer reading, so there's no RPM
+// value to publish without inventing one.
+//
+// NOTE: per-board temperatures and a live power_watts gauge also aren't
+// exposed here, and for the same reason: Stats only carries a single
+// aggregate TempMax and no live power-draw field at all, for any driver.
+// Per-board temps exist in the raw cgminer protocol response for some
+// Antminer models, but not in a shape common across drivers. Live wattage
+// is only read through GetPowerMode (some drivers, e.g. Whatsminer, fill
+// PowerMode.Watts from the device's reported power draw), which this
+// collector doesn't call; NominalWatts in models/ is a rated spec, not
+// telemetry — so there's nothing to gauge from Stats without first adding
+// real live fields to it and wiring every driver's Stats() to populate
+// them.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	for _, dev := range m.mgr.List() {
+		snap := m.snapshotFor(ctx, dev)
+		labels := []string{string(dev.ID), dev.DriverName, snap.pool}
+
+		ch <- prometheus.MustNewConstMetric(m.hashrate5sDesc, prometheus.GaugeValue, snap.stats.Hashrate5s.GHS(), labels...)
+		ch <- prometheus.MustNewConstMetric(m.hashrateAvDesc, prometheus.GaugeValue, snap.stats.HashrateAv.GHS(), labels...)
+		ch <- prometheus.MustNewConstMetric(m.tempMaxDesc, prometheus.GaugeValue, snap.stats.TempMax, labels...)
+		ch <- prometheus.MustNewConstMetric(m.acceptedDesc, prometheus.CounterValue, float64(snap.summary.Accepted), labels...)
+		ch <- prometheus.MustNewConstMetric(m.rejectedDesc, prometheus.CounterValue, float64(snap.summary.Rejected), labels...)
+	}
+}
+
+func (m *Metrics) snapshotFor(ctx context.Context, dev Device) minerSnapshot {
+	m.mu.Lock()
+	cached, ok := m.cache[dev.ID]
+	fresh := ok && time.Since(cached.at) < m.scrapeTTL
+	m.mu.Unlock()
+	if fresh {
+		return cached
+	}
+
+	next := minerSnapshot{at: time.Now()}
+	_ = m.mgr.WithSession(ctx, dev.ID, func(sess Session) error {
+		if st, err := sess.Stats(ctx); err == nil {
+			next.stats = st
+		}
+		if sm, err := sess.Summary(ctx); err == nil {
+			next.summary = sm
+		}
+		if pools, err := sess.Pools(ctx); err == nil {
+			for _, p := range pools {
+				if p.Active {
+					next.pool = p.URL
+					break
+				}
+			}
+		}
+		return nil
+	})
+
+	m.mu.Lock()
+	m.cache[dev.ID] = next
+	m.mu.Unlock()
+	return next
+}
@@ -0,0 +1,53 @@
+package models
+
+// IPolloModel carries static info about an iPollo miner model.
+type IPolloModel struct {
+	Name         string // e.g., "V1 Mini"
+	Family       string // e.g., "V1", "G1", "B1"
+	Cooling      string // air|hydro|immersion
+	Algorithm    string
+	NominalTHs   float64 // rated hashrate in TH/s
+	NominalWatts int     // rated power draw in watts
+}
+
+func (m IPolloModel) modelName() string   { return m.Name }
+func (m IPolloModel) modelFamily() string { return m.Family }
+
+// NominalHashrate returns m's rated hashrate in TH/s.
+func (m IPolloModel) NominalHashrate() float64 { return m.NominalTHs }
+
+// NominalPower returns m's rated power draw in watts.
+func (m IPolloModel) NominalPower() int { return m.NominalWatts }
+
+var ipollos = []IPolloModel{
+	{Name: "V1 Mini", Family: "V1", Cooling: "air", Algorithm: "Ethash", NominalTHs: 0.3, NominalWatts: 240},
+	{Name: "V1H", Family: "V1", Cooling: "hydro", Algorithm: "Ethash", NominalTHs: 3.6, NominalWatts: 3200},
+	{Name: "V1", Family: "V1", Cooling: "air", Algorithm: "Ethash", NominalTHs: 1.6, NominalWatts: 1350},
+	{Name: "G1 Mini", Family: "G1", Cooling: "air", Algorithm: "Scrypt", NominalTHs: 0.00032, NominalWatts: 233},
+	{Name: "G1", Family: "G1", Cooling: "air", Algorithm: "Scrypt", NominalTHs: 0.0003, NominalWatts: 800},
+	{Name: "B1L", Family: "B1", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 22, NominalWatts: 1150},
+	{Name: "B1", Family: "B1", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 40, NominalWatts: 2200},
+	{Name: "B2L", Family: "B2", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 76, NominalWatts: 3230},
+	{Name: "B2", Family: "B2", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 88, NominalWatts: 3300},
+}
+
+// ipolloFamilyFallbacks matches a bare family token like "v1" when no exact
+// model name is present in the descriptor. Ordered most-specific first.
+var ipolloFamilyFallbacks = []IPolloModel{
+	{Name: "V1", Family: "V1"},
+	{Name: "G1", Family: "G1"},
+	{Name: "B1", Family: "B1"},
+	{Name: "B2", Family: "B2"},
+}
+
+// IPollos returns the full static iPollo catalogue.
+func IPollos() []IPolloModel { return ipollos }
+
+// MatchIPollo scans descriptor text (from "miner_type"/"hardware"/"model"
+// fields) and returns a best-effort model match.
+func MatchIPollo(descriptor string) (IPolloModel, bool) {
+	if m, ok := matchLongest(descriptor, ipollos); ok {
+		return m, true
+	}
+	return matchFamily(descriptor, ipolloFamilyFallbacks)
+}
@@ -0,0 +1,97 @@
+// Package models carries static per-vendor device catalogues (family,
+// cooling variant, nominal hashrate/power, algorithm) used to turn a
+// firmware's free-text model descriptor into a known model, and to let
+// higher layers (the Prometheus exporter, a scheduler) compute expected
+// efficiency without hard-coding vendor data themselves.
+package models
+
+import "strings"
+
+// ModelLike is implemented by every per-vendor model struct so
+// matchLongest and LookupByFamily can scan any of their catalogues
+// generically instead of duplicating the same scan loop per vendor.
+type ModelLike interface {
+	modelName() string
+	modelFamily() string
+}
+
+// matchLongest scans descriptor for the longest model name present among
+// catalogue, case-insensitively. Longest wins so e.g. "S19 Pro" is
+// preferred over "S19" when both are substrings of the descriptor.
+func matchLongest[T ModelLike](descriptor string, catalogue []T) (T, bool) {
+	s := strings.ToLower(descriptor)
+
+	bestIdx := -1
+	bestLen := 0
+	for i, m := range catalogue {
+		name := m.modelName()
+		if name == "" {
+			continue
+		}
+		if idx := strings.Index(s, strings.ToLower(name)); idx >= 0 {
+			if l := len(name); l > bestLen {
+				bestLen, bestIdx = l, i
+			}
+		}
+	}
+
+	if bestIdx >= 0 {
+		return catalogue[bestIdx], true
+	}
+	var zero T
+	return zero, false
+}
+
+// matchFamily scans descriptor for any of fallbacks' family tokens, in the
+// order given; callers list their most specific families first so e.g.
+// "s19" doesn't shadow "s19k" when both would match. It's the fallback
+// matchLongest's caller reaches for once no exact model name matched.
+func matchFamily[T ModelLike](descriptor string, fallbacks []T) (T, bool) {
+	s := strings.ToLower(descriptor)
+	for _, fam := range fallbacks {
+		if containsToken(s, strings.ToLower(fam.modelFamily())) {
+			return fam, true
+		}
+	}
+	var zero T
+	return zero, false
+}
+
+// containsToken reports whether token appears in s as a delimited whole
+// token rather than a bare substring, so a single-letter family like "L"
+// doesn't match every descriptor that merely contains that letter (e.g.
+// "totally"). Both s and token are assumed already lower-cased.
+func containsToken(s, token string) bool {
+	if token == "" {
+		return false
+	}
+	for i := 0; i+len(token) <= len(s); i++ {
+		if s[i:i+len(token)] != token {
+			continue
+		}
+		if i > 0 && isWordByte(s[i-1]) {
+			continue
+		}
+		if end := i + len(token); end < len(s) && isWordByte(s[end]) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// LookupByFamily returns every model in catalogue whose family matches
+// family (case-insensitive), e.g. LookupByFamily(Whatsminers(), "M30").
+func LookupByFamily[T ModelLike](catalogue []T, family string) []T {
+	var out []T
+	for _, m := range catalogue {
+		if strings.EqualFold(m.modelFamily(), family) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package models
+
+import "testing"
+
+func TestMatchAntminer(t *testing.T) {
+	if m, ok := MatchAntminer("Antminer S19j Pro BMMiner"); !ok || m.Name != "S19j Pro" {
+		t.Errorf("expected S19j Pro match, got %+v (ok=%v)", m, ok)
+	}
+	if m, ok := MatchAntminer("unknown s21 variant"); !ok || m.Family != "S21" {
+		t.Errorf("expected family fallback to S21, got %+v (ok=%v)", m, ok)
+	}
+	if _, ok := MatchAntminer("totally unrecognized device"); ok {
+		t.Error("expected no match for unrecognized descriptor")
+	}
+}
+
+func TestMatchWhatsminer(t *testing.T) {
+	m, ok := MatchWhatsminer("M30S++")
+	if !ok || m.Name != "M30S++" {
+		t.Fatalf("expected M30S++ match, got %+v (ok=%v)", m, ok)
+	}
+	if m.NominalHashrate() <= 0 || m.NominalPower() <= 0 {
+		t.Errorf("expected nonzero nominal specs, got %+v", m)
+	}
+
+	if m, ok := MatchWhatsminer("some m60 board"); !ok || m.Family != "M60" {
+		t.Errorf("expected family fallback to M60, got %+v (ok=%v)", m, ok)
+	}
+}
+
+func TestMatchIPollo(t *testing.T) {
+	if m, ok := MatchIPollo("ipollo V1 Mini"); !ok || m.Name != "V1 Mini" {
+		t.Errorf("expected V1 Mini match, got %+v (ok=%v)", m, ok)
+	}
+}
+
+func TestLookupByFamily(t *testing.T) {
+	m30s := LookupByFamily(Whatsminers(), "M30")
+	if len(m30s) == 0 {
+		t.Fatal("expected at least one M30-family model")
+	}
+	for _, m := range m30s {
+		if m.Family != "M30" {
+			t.Errorf("LookupByFamily returned non-M30 model %+v", m)
+		}
+	}
+
+	if got := LookupByFamily(Whatsminers(), "M99"); len(got) != 0 {
+		t.Errorf("expected no models for unknown family, got %v", got)
+	}
+}
@@ -1,50 +1,70 @@
 package models
 
-import "strings"
-
+// WhatsminerModel carries static info about a MicroBT Whatsminer model.
 type WhatsminerModel struct {
-	Name      string
-	Cooling   string
-	Notes     string
+	Name         string  // e.g., "M30S++"
+	Family       string  // e.g., "M30"
+	Cooling      string  // air|hydro|immersion
+	Algorithm    string  // usually SHA-256
+	NominalTHs   float64 // rated hashrate in TH/s
+	NominalWatts int     // rated power draw in watts
 }
 
+func (m WhatsminerModel) modelName() string   { return m.Name }
+func (m WhatsminerModel) modelFamily() string { return m.Family }
+
+// NominalHashrate returns m's rated hashrate in TH/s.
+func (m WhatsminerModel) NominalHashrate() float64 { return m.NominalTHs }
+
+// NominalPower returns m's rated power draw in watts.
+func (m WhatsminerModel) NominalPower() int { return m.NominalWatts }
+
 var whatsminers = []WhatsminerModel{
-	{Name:"M1", Cooling:"air"},
-	{Name:"M3", Cooling:"air"},
-	{Name:"M10", Cooling:"air"},
-	{Name:"M20S", Cooling:"air"},
-	{Name:"M21S", Cooling:"air"},
-	{Name:"M30S", Cooling:"air"},
-	{Name:"M30S+", Cooling:"air"},
-	{Name:"M30S++", Cooling:"air"},
-	{Name:"M31S", Cooling:"air"},
-	{Name:"M31S+", Cooling:"air"},
-	{Name:"M32", Cooling:"air"},
-	{Name:"M50", Cooling:"air"},
-	{Name:"M50S", Cooling:"air"},
-	{Name:"M50S+", Cooling:"air"},
-	{Name:"M50S++", Cooling:"air"},
-	{Name:"M53", Cooling:"hydro"},
-	{Name:"M53S++", Cooling:"hydro"},
-	{Name:"M56S++", Cooling:"immersion"},
-	{Name:"M60", Cooling:"air"},
-	{Name:"M60S", Cooling:"air"},
-	{Name:"M63", Cooling:"hydro"},
-	{Name:"M63S", Cooling:"hydro"},
-	{Name:"M66", Cooling:"immersion"},
-	{Name:"M66S", Cooling:"immersion"},
-	{Name:"M70", Cooling:"air"},
+	{Name: "M20S", Family: "M20", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 68, NominalWatts: 3360},
+	{Name: "M21S", Family: "M20", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 56, NominalWatts: 3360},
+	{Name: "M30S", Family: "M30", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 88, NominalWatts: 3344},
+	{Name: "M30S+", Family: "M30", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 100, NominalWatts: 3400},
+	{Name: "M30S++", Family: "M30", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 112, NominalWatts: 3472},
+	{Name: "M31S", Family: "M30", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 76, NominalWatts: 3306},
+	{Name: "M31S+", Family: "M30", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 80, NominalWatts: 3360},
+	{Name: "M32", Family: "M30", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 62, NominalWatts: 3360},
+	{Name: "M50S++", Family: "M50", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 150, NominalWatts: 3285},
+	{Name: "M50S+", Family: "M50", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 136, NominalWatts: 3276},
+	{Name: "M50S", Family: "M50", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 126, NominalWatts: 3276},
+	{Name: "M50", Family: "M50", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 114, NominalWatts: 3306},
+	{Name: "M53S++", Family: "M53", Cooling: "hydro", Algorithm: "SHA-256", NominalTHs: 320, NominalWatts: 7040},
+	{Name: "M53", Family: "M53", Cooling: "hydro", Algorithm: "SHA-256", NominalTHs: 216, NominalWatts: 6660},
+	{Name: "M56S++", Family: "M56", Cooling: "immersion", Algorithm: "SHA-256", NominalTHs: 230, NominalWatts: 5060},
+	{Name: "M60S", Family: "M60", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 186, NominalWatts: 3344},
+	{Name: "M60", Family: "M60", Cooling: "air", Algorithm: "SHA-256", NominalTHs: 172, NominalWatts: 3328},
+	{Name: "M63S", Family: "M63", Cooling: "hydro", Algorithm: "SHA-256", NominalTHs: 406, NominalWatts: 7482},
+	{Name: "M63", Family: "M63", Cooling: "hydro", Algorithm: "SHA-256", NominalTHs: 360, NominalWatts: 7200},
+	{Name: "M66S", Family: "M66", Cooling: "immersion", Algorithm: "SHA-256", NominalTHs: 298, NominalWatts: 5513},
+	{Name: "M66", Family: "M66", Cooling: "immersion", Algorithm: "SHA-256", NominalTHs: 264, NominalWatts: 5324},
 }
 
-func MatchWhatsminer(desc string) (WhatsminerModel, bool) {
-	s := strings.ToLower(desc)
-	bestIdx := -1
-	bestLen := 0
-	for i, m := range whatsminers {
-		if idx := strings.Index(s, strings.ToLower(m.Name)); idx >= 0 {
-			if l := len(m.Name); l > bestLen { bestLen, bestIdx = l, i }
-		}
+// whatsminerFamilyFallbacks matches a bare family token like "m30" when no
+// exact model name is present in the descriptor. Ordered most-specific
+// first so e.g. "m60" doesn't shadow "m63" (and vice versa).
+var whatsminerFamilyFallbacks = []WhatsminerModel{
+	{Name: "M20", Family: "M20"},
+	{Name: "M30", Family: "M30"},
+	{Name: "M50", Family: "M50"},
+	{Name: "M53", Family: "M53"},
+	{Name: "M56", Family: "M56"},
+	{Name: "M60", Family: "M60"},
+	{Name: "M63", Family: "M63"},
+	{Name: "M66", Family: "M66"},
+}
+
+// Whatsminers returns the full static Whatsminer catalogue.
+func Whatsminers() []WhatsminerModel { return whatsminers }
+
+// MatchWhatsminer scans descriptor text (from "Type" / "miner_type" fields)
+// and returns a best-effort model match.
+func MatchWhatsminer(descriptor string) (WhatsminerModel, bool) {
+	if m, ok := matchLongest(descriptor, whatsminers); ok {
+		return m, true
 	}
-	if bestIdx >= 0 { return whatsminers[bestIdx], true }
-	return WhatsminerModel{}, false
+	return matchFamily(descriptor, whatsminerFamilyFallbacks)
 }
@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"golang.org/x/time/rate"
 )
 
 // ConnectionPool manages session connections to devices
@@ -13,27 +15,88 @@ type ConnectionPool struct {
 	maxIdle  int
 	maxOpen  int
 	idleTime time.Duration
+	onOpen   func()
+
+	breakerThreshold int
+	baseBackoff      time.Duration
+	maxBackoff       time.Duration
+	onBreakerChange  func(MinerID, Condition)
+	logger           Logger
+
+	minIdle             int
+	healthCheckInterval time.Duration
+	healthCheck         func(ctx context.Context, sess Session) error
+
+	rateLimit float64
+	rateBurst int
 }
 
+// circuitState is the per-device circuit breaker state machine: closed
+// (normal operation) -> open (failing fast, waiting out a backoff) ->
+// halfOpen (one probe allowed through) -> back to closed on success or
+// open on failure.
+type circuitState int
+
+const (
+	breakerClosed circuitState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
 // DevicePool holds connections for a single device
 type DevicePool struct {
-	mu        sync.Mutex
-	device    *Device
-	idle      []Session
-	active    map[Session]bool
-	createdAt map[Session]time.Time
-	maxIdle   int
-	maxOpen   int
-	idleTime  time.Duration
+	mu         sync.Mutex
+	device     *Device
+	idle       []Session
+	active     map[Session]bool
+	createdAt  map[Session]time.Time
+	lastUsedAt map[Session]time.Time
+	maxIdle    int
+	maxOpen    int
+	idleTime   time.Duration
+	onOpen     func()
+
+	breakerState        circuitState
+	breakerThreshold    int
+	baseBackoff         time.Duration
+	maxBackoff          time.Duration
+	consecutiveFailures int
+	backoff             time.Duration
+	openedAt            time.Time
+	permanentlyTripped  bool
+	halfOpenInFlight    bool
+	onBreakerChange     func(MinerID, Condition)
+	logger              Logger
+
+	minIdle           int
+	driverHealthCheck func(ctx context.Context, sess Session) error
+	healthCheck       func(ctx context.Context, sess Session) error
+
+	// waiters are FIFO-queued GetSession callers that arrived while
+	// active was already at maxOpen. returnSession hands a freed session
+	// straight to waiters[0] instead of idling it, so queued callers are
+	// served in order as soon as one becomes available.
+	waiters []chan Session
+
+	limiter              *rate.Limiter
+	rejectedDueToLimiter int64
+
+	waitCount int64
+	totalWait time.Duration
+	maxWait   time.Duration
 }
 
 // NewConnectionPool creates a new connection pool
 func NewConnectionPool() *ConnectionPool {
 	return &ConnectionPool{
-		pools:    make(map[MinerID]*DevicePool),
-		maxIdle:  5,
-		maxOpen:  10,
-		idleTime: 5 * time.Minute,
+		pools:            make(map[MinerID]*DevicePool),
+		maxIdle:          5,
+		maxOpen:          10,
+		idleTime:         5 * time.Minute,
+		breakerThreshold: 3,
+		baseBackoff:      5 * time.Second,
+		maxBackoff:       2 * time.Minute,
+		logger:           &NoOpLogger{},
 	}
 }
 
@@ -46,40 +109,188 @@ func (p *ConnectionPool) SetLimits(maxIdle, maxOpen int, idleTime time.Duration)
 	p.idleTime = idleTime
 }
 
+// SetBreakerLimits configures the per-device circuit breaker: threshold is
+// how many consecutive Transient failures trip the breaker open; baseBackoff
+// is how long the first open period lasts, doubling (capped at maxBackoff)
+// each time a half-open probe fails.
+func (p *ConnectionPool) SetBreakerLimits(threshold int, baseBackoff, maxBackoff time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.breakerThreshold = threshold
+	p.baseBackoff = baseBackoff
+	p.maxBackoff = maxBackoff
+}
+
+// SetOnSessionOpen registers fn to be called whenever the pool opens a brand
+// new session to a device (as opposed to reusing an idle one). It's used to
+// feed the multiminer_session_open_total metric; fn may be nil to detach.
+func (p *ConnectionPool) SetOnSessionOpen(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onOpen = fn
+}
+
+// SetOnBreakerChange registers fn to be called whenever a device's circuit
+// breaker condition changes (closed/open/half-open/permanently tripped), so
+// callers can mirror it into the device's condition subsystem. fn may be
+// nil to detach.
+func (p *ConnectionPool) SetOnBreakerChange(fn func(MinerID, Condition)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onBreakerChange = fn
+}
+
+// SetLogger registers logger to receive session-open and breaker-transition
+// events for every device in the pool, tagged with that device's MinerID as
+// "miner_id". logger may be nil to fall back to a NoOpLogger.
+func (p *ConnectionPool) SetLogger(logger Logger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if logger == nil {
+		logger = &NoOpLogger{}
+	}
+	p.logger = logger
+}
+
+// SetMinIdle sets how many sessions per device (idle plus in use) Run tops
+// up with pre-warmed idle ones after a health-check pass. <= 0 disables
+// pre-warming (the default).
+func (p *ConnectionPool) SetMinIdle(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.minIdle = n
+}
+
+// SetHealthCheckInterval sets how often Run probes idle sessions. <= 0
+// uses a 30s default.
+func (p *ConnectionPool) SetHealthCheckInterval(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healthCheckInterval = d
+}
+
+// SetHealthCheck overrides the liveness probe Run uses for every device,
+// regardless of whether its driver implements SessionHealthChecker. fn may
+// be nil to go back to per-driver detection.
+func (p *ConnectionPool) SetHealthCheck(fn func(ctx context.Context, sess Session) error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healthCheck = fn
+}
+
+// SetRateLimit configures a per-device token-bucket: GetSession calls
+// against a single device are capped at rps, with room for a burst up to
+// burst above that, so a fleet-wide stats-polling loop can't hammer any one
+// miner's socket (cgminer's TCP listener in particular stalls under
+// concurrent "stats" calls). A call that exceeds the bucket fails fast with
+// a Retryable-classified "rate limit exceeded" error rather than opening or
+// reusing a session; see
+// PoolStats.RejectedDueToLimiter. rps <= 0 disables rate limiting (the
+// default).
+func (p *ConnectionPool) SetRateLimit(rps float64, burst int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rateLimit = rps
+	p.rateBurst = burst
+}
+
+// applyRateLimitLocked brings dp's limiter in line with p's current
+// configuration, creating or dropping it as needed. Must be called with
+// dp.mu held.
+func (p *ConnectionPool) applyRateLimitLocked(dp *DevicePool) {
+	if p.rateLimit <= 0 {
+		dp.limiter = nil
+		return
+	}
+	if dp.limiter == nil {
+		dp.limiter = rate.NewLimiter(rate.Limit(p.rateLimit), p.rateBurst)
+		return
+	}
+	dp.limiter.SetLimit(rate.Limit(p.rateLimit))
+	dp.limiter.SetBurst(p.rateBurst)
+}
+
 // GetSession retrieves a session from the pool or creates a new one
 func (p *ConnectionPool) GetSession(ctx context.Context, id MinerID, device *Device) (Session, error) {
 	p.mu.Lock()
 	pool, exists := p.pools[id]
 	if !exists {
 		pool = &DevicePool{
-			device:    device,
-			active:    make(map[Session]bool),
-			createdAt: make(map[Session]time.Time),
-			maxIdle:   p.maxIdle,
-			maxOpen:   p.maxOpen,
-			idleTime:  p.idleTime,
+			device:            device,
+			active:            make(map[Session]bool),
+			createdAt:         make(map[Session]time.Time),
+			lastUsedAt:        make(map[Session]time.Time),
+			maxIdle:           p.maxIdle,
+			maxOpen:           p.maxOpen,
+			idleTime:          p.idleTime,
+			onOpen:            p.onOpen,
+			breakerThreshold:  p.breakerThreshold,
+			baseBackoff:       p.baseBackoff,
+			maxBackoff:        p.maxBackoff,
+			onBreakerChange:   p.onBreakerChange,
+			logger:            p.logger.WithFields(F("miner_id", string(id))),
+			minIdle:           p.minIdle,
+			driverHealthCheck: defaultHealthCheck(device.Driver),
+			healthCheck:       p.healthCheck,
 		}
+		p.applyRateLimitLocked(pool)
 		p.pools[id] = pool
+	} else {
+		pool.mu.Lock()
+		pool.onOpen = p.onOpen
+		pool.breakerThreshold = p.breakerThreshold
+		pool.baseBackoff = p.baseBackoff
+		pool.maxBackoff = p.maxBackoff
+		pool.onBreakerChange = p.onBreakerChange
+		pool.logger = p.logger.WithFields(F("miner_id", string(id)))
+		pool.minIdle = p.minIdle
+		pool.healthCheck = p.healthCheck
+		p.applyRateLimitLocked(pool)
+		pool.mu.Unlock()
 	}
 	p.mu.Unlock()
 
 	return pool.getSession(ctx)
 }
 
-// ReturnSession returns a session to the pool
-func (p *ConnectionPool) ReturnSession(id MinerID, sess Session) {
+// ReturnSession returns a session to the pool. err, if non-nil, is the
+// outcome of whatever the caller did with the session; it feeds the
+// device's circuit breaker (see MultiMinerError.Classification) but
+// doesn't otherwise affect whether the session itself is reused.
+func (p *ConnectionPool) ReturnSession(id MinerID, sess Session, err error) {
 	p.mu.RLock()
 	pool, exists := p.pools[id]
 	p.mu.RUnlock()
 
 	if exists {
-		pool.returnSession(sess)
+		pool.returnSession(sess, err)
 	} else {
 		// Pool doesn't exist anymore, close session
 		sess.Close()
 	}
 }
 
+// Reset clears id's circuit breaker, closing it and forgetting any
+// permanent trip. Intended for an operator to call once the underlying
+// problem (bad credentials, firmware bug) has been fixed.
+func (p *ConnectionPool) Reset(id MinerID) {
+	p.mu.RLock()
+	pool, exists := p.pools[id]
+	p.mu.RUnlock()
+	if !exists {
+		return
+	}
+
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.breakerState = breakerClosed
+	pool.permanentlyTripped = false
+	pool.consecutiveFailures = 0
+	pool.backoff = 0
+	pool.halfOpenInFlight = false
+	pool.setBreakerConditionLocked(ConditionTrue, SeverityInfo, ReasonCircuitClosed, "breaker reset by operator")
+}
+
 // CleanUp removes expired idle connections
 func (p *ConnectionPool) CleanUp() {
 	p.mu.RLock()
@@ -105,42 +316,277 @@ func (p *ConnectionPool) Close() {
 	p.pools = make(map[MinerID]*DevicePool)
 }
 
-// getSession gets a session from the device pool
+// breakerCheckLocked reports whether a new attempt may proceed given the
+// device's current circuit breaker state, transitioning open->half-open
+// once the backoff has elapsed. Must be called with dp.mu held.
+func (dp *DevicePool) breakerCheckLocked() error {
+	if dp.permanentlyTripped {
+		return NewPermanentError("circuit breaker open", "device permanently failing; call Reset to clear", nil)
+	}
+
+	switch dp.breakerState {
+	case breakerOpen:
+		if time.Since(dp.openedAt) < dp.backoff {
+			return NewRetryableError("circuit breaker open", "device failing repeatedly, backing off", nil)
+		}
+		dp.breakerState = breakerHalfOpen
+		dp.halfOpenInFlight = true
+		dp.setBreakerConditionLocked(ConditionUnknown, SeverityWarning, ReasonCircuitHalfOpen, "probing after backoff")
+		return nil
+	case breakerHalfOpen:
+		if dp.halfOpenInFlight {
+			return NewRetryableError("circuit breaker half-open", "a probe is already in flight", nil)
+		}
+		dp.halfOpenInFlight = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// recordFailureLocked updates breaker state for a failed attempt. Only
+// errors classified Permanent or Transient affect the breaker; unclassified
+// and Retryable errors pass through untouched. Must be called with dp.mu
+// held.
+func (dp *DevicePool) recordFailureLocked(err error) {
+	if err == nil {
+		return
+	}
+	if IsPermanent(err) {
+		dp.permanentlyTripped = true
+		dp.breakerState = breakerOpen
+		dp.halfOpenInFlight = false
+		dp.setBreakerConditionLocked(ConditionFalse, SeverityError, ReasonPermanentFailure, err.Error())
+		return
+	}
+
+	mErr, ok := IsMultiMinerError(err)
+	if !ok || mErr.Classification != ClassificationTransient {
+		return
+	}
+
+	if dp.breakerState == breakerHalfOpen {
+		// The probe failed: reopen with a longer backoff.
+		dp.halfOpenInFlight = false
+		dp.breakerState = breakerOpen
+		dp.openedAt = time.Now()
+		dp.backoff = nextBackoff(dp.backoff, dp.maxBackoff)
+		dp.setBreakerConditionLocked(ConditionFalse, SeverityWarning, ReasonCircuitOpen, err.Error())
+		return
+	}
+
+	dp.consecutiveFailures++
+	threshold := dp.breakerThreshold
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if dp.breakerState == breakerClosed && dp.consecutiveFailures >= threshold {
+		dp.breakerState = breakerOpen
+		dp.openedAt = time.Now()
+		dp.backoff = dp.baseBackoff
+		dp.setBreakerConditionLocked(ConditionFalse, SeverityWarning, ReasonCircuitOpen, err.Error())
+	}
+}
+
+// recordSuccessLocked clears failure accounting and closes the breaker if
+// it wasn't already closed. Must be called with dp.mu held.
+func (dp *DevicePool) recordSuccessLocked() {
+	dp.halfOpenInFlight = false
+	dp.consecutiveFailures = 0
+	if dp.breakerState != breakerClosed || dp.permanentlyTripped {
+		dp.breakerState = breakerClosed
+		dp.permanentlyTripped = false
+		dp.backoff = 0
+		dp.setBreakerConditionLocked(ConditionTrue, SeverityInfo, ReasonCircuitClosed, "")
+	}
+}
+
+// loggerLocked returns dp's logger, defaulting to a NoOpLogger for pools
+// created before SetLogger existed. Must be called with dp.mu held.
+func (dp *DevicePool) loggerLocked() Logger {
+	if dp.logger == nil {
+		return &NoOpLogger{}
+	}
+	return dp.logger
+}
+
+// setBreakerConditionLocked reports a breaker transition via onBreakerChange,
+// if one is registered, and always logs it. Must be called with dp.mu held.
+func (dp *DevicePool) setBreakerConditionLocked(status ConditionStatus, severity ConditionSeverity, reason, message string) {
+	fields := []Field{F("status", status), F("reason", reason), F("message", message)}
+	switch severity {
+	case SeverityError:
+		dp.loggerLocked().Error(context.Background(), "circuit breaker transition", fields...)
+	case SeverityWarning:
+		dp.loggerLocked().Warn(context.Background(), "circuit breaker transition", fields...)
+	default:
+		dp.loggerLocked().Info(context.Background(), "circuit breaker transition", fields...)
+	}
+
+	if dp.onBreakerChange == nil {
+		return
+	}
+	dp.onBreakerChange(dp.device.ID, Condition{
+		Type:     CircuitBreaker,
+		Status:   status,
+		Severity: severity,
+		Reason:   reason,
+		Message:  message,
+	})
+}
+
+// nextBackoff doubles cur, capped at max (when max > 0).
+func nextBackoff(cur, max time.Duration) time.Duration {
+	if cur <= 0 {
+		return 0
+	}
+	next := cur * 2
+	if max > 0 && next > max {
+		return max
+	}
+	return next
+}
+
+// getSession gets a session from the device pool. If active is already at
+// maxOpen, the caller is queued as a FIFO waiter (see waitForSessionLocked)
+// instead of failing fast.
 func (dp *DevicePool) getSession(ctx context.Context) (Session, error) {
+	start := time.Now()
 	dp.mu.Lock()
 	defer dp.mu.Unlock()
+	defer dp.recordWaitLocked(start)
+
+	if err := dp.breakerCheckLocked(); err != nil {
+		return nil, err
+	}
+
+	if dp.limiter != nil && !dp.limiter.Allow() {
+		dp.rejectedDueToLimiter++
+		return nil, NewRetryableError("rate limit exceeded", "too many requests to this device, slow down", nil)
+	}
 
 	// Try to get an idle session
 	if len(dp.idle) > 0 {
 		sess := dp.idle[len(dp.idle)-1]
 		dp.idle = dp.idle[:len(dp.idle)-1]
 		dp.active[sess] = true
+		dp.lastUsedAt[sess] = time.Now()
+		return sess, nil
+	}
+
+	// Create new session if there's room
+	if len(dp.active) < dp.maxOpen {
+		sess, err := dp.device.Driver.Open(ctx, dp.device.Endpoint)
+		if err != nil {
+			dp.recordFailureLocked(err)
+			return nil, err
+		}
+
+		now := time.Now()
+		dp.active[sess] = true
+		dp.createdAt[sess] = now
+		dp.lastUsedAt[sess] = now
+		dp.loggerLocked().Debug(ctx, "opened new session")
+		if dp.onOpen != nil {
+			dp.onOpen()
+		}
 		return sess, nil
 	}
 
-	// Check if we can create a new session
-	if len(dp.active) >= dp.maxOpen {
-		return nil, NewDeviceError("connection pool exhausted", "too many active connections", nil)
+	return dp.waitForSessionLocked(ctx)
+}
+
+// waitForSessionLocked queues the caller as a FIFO waiter for the next
+// session returnSession hands back. It blocks until one arrives, ctx is
+// canceled, ctx's deadline (if any) elapses, or the pool is closed. Must be
+// called with dp.mu held; returns with dp.mu held.
+func (dp *DevicePool) waitForSessionLocked(ctx context.Context) (Session, error) {
+	ch := make(chan Session, 1)
+	dp.waiters = append(dp.waiters, ch)
+	dp.mu.Unlock()
+
+	var timeoutCh <-chan time.Time
+	if deadline, ok := ctx.Deadline(); ok {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeoutCh = timer.C
 	}
 
-	// Create new session
-	sess, err := dp.device.Driver.Open(ctx, dp.device.Endpoint)
-	if err != nil {
-		return nil, err
+	var sess Session
+	var err error
+	select {
+	case sess = <-ch:
+		if sess == nil {
+			err = NewPermanentError("connection pool closed", "pool was closed while waiting for a session", nil)
+		}
+	case <-ctx.Done():
+		err = dp.abandonWait(ch, ctx.Err())
+	case <-timeoutCh:
+		err = dp.abandonWait(ch, NewRetryableError("connection pool exhausted", "timed out waiting for an available connection", nil))
 	}
 
-	dp.active[sess] = true
-	dp.createdAt[sess] = time.Now()
-	return sess, nil
+	dp.mu.Lock()
+	return sess, err
+}
+
+// abandonWait removes ch from the waiter queue so returnSession won't hand
+// it a session after the caller has stopped waiting. If a handoff already
+// raced ahead of the removal, the session arrives on ch anyway; it's
+// returned to the pool unused instead of leaked, and fallback is still what
+// gets surfaced to the caller that gave up.
+func (dp *DevicePool) abandonWait(ch chan Session, fallback error) error {
+	dp.mu.Lock()
+	for i, w := range dp.waiters {
+		if w == ch {
+			dp.waiters = append(dp.waiters[:i], dp.waiters[i+1:]...)
+			dp.mu.Unlock()
+			return fallback
+		}
+	}
+	dp.mu.Unlock()
+
+	if sess := <-ch; sess != nil {
+		dp.returnSession(sess, nil)
+	}
+	return fallback
+}
+
+// recordWaitLocked accumulates how long a getSession call took to acquire a
+// session, feeding PoolStats' wait-time fields. Must be called with dp.mu
+// held.
+func (dp *DevicePool) recordWaitLocked(start time.Time) {
+	wait := time.Since(start)
+	dp.waitCount++
+	dp.totalWait += wait
+	if wait > dp.maxWait {
+		dp.maxWait = wait
+	}
 }
 
-// returnSession returns a session to the device pool
-func (dp *DevicePool) returnSession(sess Session) {
+// returnSession returns a session to the device pool, updating the circuit
+// breaker for the outcome (err) of whatever the caller did with it.
+func (dp *DevicePool) returnSession(sess Session, err error) {
 	dp.mu.Lock()
 	defer dp.mu.Unlock()
 
+	if err != nil {
+		dp.recordFailureLocked(err)
+	} else {
+		dp.recordSuccessLocked()
+	}
+
 	// Remove from active
 	delete(dp.active, sess)
+	dp.lastUsedAt[sess] = time.Now()
+
+	// Hand off directly to the oldest waiter, if any, instead of idling.
+	if len(dp.waiters) > 0 {
+		ch := dp.waiters[0]
+		dp.waiters = dp.waiters[1:]
+		dp.active[sess] = true
+		ch <- sess
+		return
+	}
 
 	// Add to idle pool if there's space
 	if len(dp.idle) < dp.maxIdle {
@@ -149,10 +595,14 @@ func (dp *DevicePool) returnSession(sess Session) {
 		// Pool is full, close the session
 		sess.Close()
 		delete(dp.createdAt, sess)
+		delete(dp.lastUsedAt, sess)
 	}
 }
 
-// cleanExpired removes expired idle connections
+// cleanExpired removes idle connections that haven't been used in longer
+// than idleTime. Eviction is based on lastUsedAt rather than createdAt, so a
+// long-lived connection that's still in active rotation isn't evicted just
+// because it was opened a while ago.
 func (dp *DevicePool) cleanExpired() {
 	dp.mu.Lock()
 	defer dp.mu.Unlock()
@@ -161,16 +611,16 @@ func (dp *DevicePool) cleanExpired() {
 	validIdle := make([]Session, 0, len(dp.idle))
 
 	for _, sess := range dp.idle {
-		if createdAt, exists := dp.createdAt[sess]; exists {
-			if now.Sub(createdAt) < dp.idleTime {
-				validIdle = append(validIdle, sess)
-			} else {
-				sess.Close()
-				delete(dp.createdAt, sess)
-			}
+		lastUsed, exists := dp.lastUsedAt[sess]
+		if !exists {
+			lastUsed = dp.createdAt[sess]
+		}
+		if now.Sub(lastUsed) < dp.idleTime {
+			validIdle = append(validIdle, sess)
 		} else {
-			// No creation time, consider it expired
 			sess.Close()
+			delete(dp.createdAt, sess)
+			delete(dp.lastUsedAt, sess)
 		}
 	}
 
@@ -192,8 +642,17 @@ func (dp *DevicePool) closeAll() {
 	for sess := range dp.active {
 		sess.Close()
 	}
+	dp.lastUsedAt = make(map[Session]time.Time)
 	dp.active = make(map[Session]bool)
 	dp.createdAt = make(map[Session]time.Time)
+
+	// Wake any callers still queued in waitForSessionLocked so they don't
+	// block forever; a closed channel receives as nil, which getSession
+	// reports as a pool-closed error.
+	for _, ch := range dp.waiters {
+		close(ch)
+	}
+	dp.waiters = nil
 }
 
 // Stats returns pool statistics
@@ -204,12 +663,20 @@ func (p *ConnectionPool) Stats() map[MinerID]PoolStats {
 	stats := make(map[MinerID]PoolStats)
 	for id, pool := range p.pools {
 		pool.mu.Lock()
-		stats[id] = PoolStats{
-			ActiveConnections: len(pool.active),
-			IdleConnections:   len(pool.idle),
-			MaxOpen:           pool.maxOpen,
-			MaxIdle:           pool.maxIdle,
+		s := PoolStats{
+			ActiveConnections:    len(pool.active),
+			IdleConnections:      len(pool.idle),
+			MaxOpen:              pool.maxOpen,
+			MaxIdle:              pool.maxIdle,
+			WaitCount:            pool.waitCount,
+			TotalWaitDuration:    pool.totalWait,
+			MaxWaitDuration:      pool.maxWait,
+			RejectedDueToLimiter: pool.rejectedDueToLimiter,
 		}
+		if pool.waitCount > 0 {
+			s.AvgWaitDuration = pool.totalWait / time.Duration(pool.waitCount)
+		}
+		stats[id] = s
 		pool.mu.Unlock()
 	}
 	return stats
@@ -221,4 +688,129 @@ type PoolStats struct {
 	IdleConnections   int `json:"idle_connections"`
 	MaxOpen           int `json:"max_open"`
 	MaxIdle           int `json:"max_idle"`
+
+	// WaitCount is how many GetSession calls this device has served, each
+	// contributing one sample to the wait-time fields below.
+	WaitCount int64 `json:"wait_count"`
+	// TotalWaitDuration is the cumulative time GetSession callers spent
+	// acquiring a session (idle reuse or a fresh Open).
+	TotalWaitDuration time.Duration `json:"total_wait_duration"`
+	// AvgWaitDuration is TotalWaitDuration / WaitCount, zero if WaitCount is 0.
+	AvgWaitDuration time.Duration `json:"avg_wait_duration"`
+	// MaxWaitDuration is the slowest single GetSession call observed.
+	MaxWaitDuration time.Duration `json:"max_wait_duration"`
+	// RejectedDueToLimiter is how many GetSession calls this device has
+	// failed fast with a rate-limit error instead of waiting or opening a
+	// session. See (*ConnectionPool).SetRateLimit.
+	RejectedDueToLimiter int64 `json:"rejected_due_to_limiter"`
+}
+
+// defaultHealthCheck resolves the liveness probe a device's driver supports:
+// its own SessionHealthChecker if implemented, otherwise a generic probe via
+// Session.Model.
+func defaultHealthCheck(driver Driver) func(ctx context.Context, sess Session) error {
+	if hc, ok := driver.(SessionHealthChecker); ok {
+		return hc.HealthCheck
+	}
+	return func(ctx context.Context, sess Session) error {
+		_, err := sess.Model(ctx)
+		return err
+	}
+}
+
+// Run starts the pool's background janitor: every health-check interval
+// (see SetHealthCheckInterval, default 30s) it pings each device's idle
+// sessions, discards any that fail the probe, and pre-warms replacements up
+// to minIdle (see SetMinIdle). It blocks until ctx is done, so callers
+// should run it in its own goroutine, e.g. `go pool.Run(ctx)`.
+func (p *ConnectionPool) Run(ctx context.Context) {
+	p.mu.RLock()
+	interval := p.healthCheckInterval
+	p.mu.RUnlock()
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.runHealthCheckPass(ctx)
+		}
+	}
+}
+
+// runHealthCheckPass snapshots the current device pools and health-checks
+// each one in turn; it's split out from Run so a single pass can also be
+// driven directly in tests.
+func (p *ConnectionPool) runHealthCheckPass(ctx context.Context) {
+	p.mu.RLock()
+	pools := make([]*DevicePool, 0, len(p.pools))
+	for _, pool := range p.pools {
+		pools = append(pools, pool)
+	}
+	p.mu.RUnlock()
+
+	for _, pool := range pools {
+		pool.healthCheckAndWarm(ctx)
+	}
+}
+
+// healthCheckAndWarm pings every idle session with the device's health
+// check, discards the ones that fail, then opens fresh sessions (bounded by
+// maxOpen) until idle+active reaches minIdle.
+func (dp *DevicePool) healthCheckAndWarm(ctx context.Context) {
+	dp.mu.Lock()
+	check := dp.healthCheck
+	if check == nil {
+		check = dp.driverHealthCheck
+	}
+	idle := dp.idle
+	dp.idle = nil
+	dp.mu.Unlock()
+
+	alive := make([]Session, 0, len(idle))
+	for _, sess := range idle {
+		if err := check(ctx, sess); err != nil {
+			dp.mu.Lock()
+			dp.loggerLocked().Warn(ctx, "idle session failed health check, discarding", F("error", err))
+			delete(dp.createdAt, sess)
+			delete(dp.lastUsedAt, sess)
+			dp.mu.Unlock()
+			sess.Close()
+			continue
+		}
+		alive = append(alive, sess)
+	}
+
+	dp.mu.Lock()
+	dp.idle = append(alive, dp.idle...)
+	device := dp.device
+	minIdle := dp.minIdle
+	toOpen := minIdle - len(dp.idle) - len(dp.active)
+	if room := dp.maxOpen - len(dp.active) - len(dp.idle); toOpen > room {
+		toOpen = room
+	}
+	dp.mu.Unlock()
+
+	for i := 0; i < toOpen; i++ {
+		sess, err := device.Driver.Open(ctx, device.Endpoint)
+		if err != nil {
+			dp.mu.Lock()
+			dp.loggerLocked().Warn(ctx, "failed to pre-warm idle session", F("error", err))
+			dp.mu.Unlock()
+			return
+		}
+
+		now := time.Now()
+		dp.mu.Lock()
+		dp.idle = append(dp.idle, sess)
+		dp.createdAt[sess] = now
+		dp.lastUsedAt[sess] = now
+		dp.mu.Unlock()
+	}
 }
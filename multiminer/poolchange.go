@@ -0,0 +1,58 @@
+package multiminer
+
+// poolOp identifies the mutation mergePoolChange should apply to a pool
+// list. It exists for drivers whose firmware only accepts the whole pool
+// list atomically (Whatsminer's "update_pools", iPollo's POST /api/pools)
+// rather than per-entry add/enable/disable/remove/switch commands.
+type poolOp string
+
+const (
+	poolOpAdd     poolOp = "add"
+	poolOpEnable  poolOp = "enable"
+	poolOpDisable poolOp = "disable"
+	poolOpRemove  poolOp = "remove"
+	poolOpSwitch  poolOp = "switch"
+)
+
+// mergePoolChange applies op to existing and returns the full pool list a
+// driver should resubmit to an atomic "replace everything" write command.
+// For poolOpAdd, newPool is appended as-is; for every other op, only
+// newPool.ID is used to locate the target entry within existing.
+func mergePoolChange(existing []Pool, op poolOp, newPool Pool) []Pool {
+	switch op {
+	case poolOpAdd:
+		merged := make([]Pool, 0, len(existing)+1)
+		merged = append(merged, existing...)
+		return append(merged, newPool)
+	case poolOpRemove:
+		merged := make([]Pool, 0, len(existing))
+		for _, p := range existing {
+			if p.ID != newPool.ID {
+				merged = append(merged, p)
+			}
+		}
+		return merged
+	case poolOpEnable, poolOpDisable, poolOpSwitch:
+		merged := make([]Pool, len(existing))
+		copy(merged, existing)
+		for i := range merged {
+			switch op {
+			case poolOpEnable:
+				if merged[i].ID == newPool.ID {
+					merged[i].Active = true
+				}
+			case poolOpDisable:
+				if merged[i].ID == newPool.ID {
+					merged[i].Active = false
+				}
+			case poolOpSwitch:
+				// Activating one pool deactivates the rest, matching how
+				// cgminer-derived firmwares report "Stratum Active".
+				merged[i].Active = merged[i].ID == newPool.ID
+			}
+		}
+		return merged
+	default:
+		return existing
+	}
+}
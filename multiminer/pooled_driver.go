@@ -0,0 +1,380 @@
+package multiminer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// BreakerState is the exported form of a PooledDriver endpoint's circuit
+// breaker, for metrics/alerting consumers (e.g. SessionMetrics or a
+// Manager's condition subsystem) that want "miner offline" instead of an
+// error storm.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// PoolOpts configures NewPooledDriver's per-endpoint session reuse and
+// circuit breaker.
+type PoolOpts struct {
+	// BreakerThreshold is how many consecutive connection failures (errors
+	// carrying ErrCodeConnectionFailed, i.e. built with NewConnectionError)
+	// trip an endpoint's breaker open. <= 0 uses a default of 3.
+	BreakerThreshold int
+	// BreakerCooldown is how long the breaker stays open before allowing a
+	// single half-open probe through. <= 0 uses a default of 30s.
+	BreakerCooldown time.Duration
+	// IdleTimeout closes and forgets a pooled session that hasn't been used
+	// for this long, so a device that's gone for good doesn't keep a dead
+	// TCP connection around forever. <= 0 uses a default of 5 minutes.
+	IdleTimeout time.Duration
+	// OnBreakerChange, if set, is called whenever an endpoint's breaker
+	// transitions, keyed by the endpoint address.
+	OnBreakerChange func(endpoint string, state BreakerState)
+}
+
+func (o PoolOpts) threshold() int {
+	if o.BreakerThreshold <= 0 {
+		return 3
+	}
+	return o.BreakerThreshold
+}
+
+func (o PoolOpts) cooldown() time.Duration {
+	if o.BreakerCooldown <= 0 {
+		return 30 * time.Second
+	}
+	return o.BreakerCooldown
+}
+
+func (o PoolOpts) idleTimeout() time.Duration {
+	if o.IdleTimeout <= 0 {
+		return 5 * time.Minute
+	}
+	return o.IdleTimeout
+}
+
+// pooledEndpoint multiplexes every logical Open/Close pair for one endpoint
+// address onto a single real Session, so its underlying TCP dialer and
+// http.Client (e.g. luxOSSession's cgClient/httpClient) are stood up once
+// and reused instead of torn down every poll. It also carries that
+// endpoint's circuit breaker state.
+type pooledEndpoint struct {
+	mu       sync.Mutex
+	sess     Session
+	lastUsed time.Time
+
+	state        BreakerState
+	failures     int
+	openedAt     time.Time
+	halfOpenBusy bool
+}
+
+// acquire returns pe's shared real session, opening one via open if none
+// exists yet, after checking/transitioning the circuit breaker. It returns
+// an error without calling open if the breaker is open and still cooling
+// down, or if a half-open probe is already in flight.
+func (pe *pooledEndpoint) acquire(ctx context.Context, open func(context.Context) (Session, error), opt PoolOpts, notify func(BreakerState)) (Session, error) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+
+	switch pe.state {
+	case BreakerOpen:
+		if time.Since(pe.openedAt) < opt.cooldown() {
+			return nil, NewRetryableError("circuit breaker open", "endpoint failing repeatedly, backing off", nil)
+		}
+		pe.state = BreakerHalfOpen
+		pe.halfOpenBusy = true
+		pe.notifyLocked(notify)
+	case BreakerHalfOpen:
+		if pe.halfOpenBusy {
+			return nil, NewRetryableError("circuit breaker half-open", "a probe is already in flight", nil)
+		}
+		pe.halfOpenBusy = true
+	}
+
+	if pe.sess == nil {
+		sess, err := open(ctx)
+		if err != nil {
+			pe.recordLocked(err, opt, notify)
+			return nil, err
+		}
+		pe.sess = sess
+	}
+	pe.lastUsed = time.Now()
+	return pe.sess, nil
+}
+
+// recordLocked updates the breaker for the outcome of a call against pe's
+// real session. Only connection failures (NewConnectionError) count
+// towards the breaker; other errors (bad input, not implemented, a
+// firmware-level rejection) pass through untouched. Must be called with
+// pe.mu held.
+func (pe *pooledEndpoint) recordLocked(err error, opt PoolOpts, notify func(BreakerState)) {
+	if err == nil {
+		pe.halfOpenBusy = false
+		pe.failures = 0
+		if pe.state != BreakerClosed {
+			pe.state = BreakerClosed
+			pe.notifyLocked(notify)
+		}
+		return
+	}
+
+	if !isConnectionError(err) {
+		return
+	}
+
+	if pe.state == BreakerHalfOpen {
+		// The probe failed: reopen and drop the dead session so the next
+		// attempt (after cooldown) dials fresh.
+		pe.halfOpenBusy = false
+		pe.state = BreakerOpen
+		pe.openedAt = time.Now()
+		pe.closeSessionLocked()
+		pe.notifyLocked(notify)
+		return
+	}
+
+	pe.failures++
+	if pe.state == BreakerClosed && pe.failures >= opt.threshold() {
+		pe.state = BreakerOpen
+		pe.openedAt = time.Now()
+		pe.closeSessionLocked()
+		pe.notifyLocked(notify)
+	}
+}
+
+func (pe *pooledEndpoint) closeSessionLocked() {
+	if pe.sess != nil {
+		_ = pe.sess.Close()
+		pe.sess = nil
+	}
+}
+
+func (pe *pooledEndpoint) notifyLocked(notify func(BreakerState)) {
+	if notify != nil {
+		notify(pe.state)
+	}
+}
+
+// expired reports whether pe has had no activity for longer than idle,
+// meaning its real session (and any TCP connection it holds open) can be
+// closed and forgotten.
+func (pe *pooledEndpoint) expired(idle time.Time) bool {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	return pe.sess != nil && pe.lastUsed.Before(idle)
+}
+
+// pooledDriver wraps a Driver so repeated Open calls to the same endpoint
+// share one underlying Session (and therefore its dialer/http.Client)
+// instead of standing up a new one every call, and gates every method
+// behind a per-endpoint circuit breaker.
+type pooledDriver struct {
+	base Driver
+	opt  PoolOpts
+
+	mu        sync.Mutex
+	endpoints map[string]*pooledEndpoint
+}
+
+// NewPooledDriver wraps base so sessions to the same endpoint reuse one
+// underlying connection and share a circuit breaker, without requiring
+// callers to change how they use Driver/Session. It's meant for monitoring
+// loops that call Model -> Stats -> Summary -> Pools on a tight interval
+// (e.g. every 15s for /metrics) and would otherwise tear down and redial a
+// device's TCP connection each cycle.
+func NewPooledDriver(base Driver, opts PoolOpts) Driver {
+	return &pooledDriver{base: base, opt: opts, endpoints: make(map[string]*pooledEndpoint)}
+}
+
+func (d *pooledDriver) Name() string { return d.base.Name() }
+func (d *pooledDriver) Detect(ctx context.Context, ep Endpoint) (bool, error) {
+	return d.base.Detect(ctx, ep)
+}
+func (d *pooledDriver) Capabilities() Capability { return d.base.Capabilities() }
+
+func (d *pooledDriver) endpointFor(address string) *pooledEndpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pe, ok := d.endpoints[address]
+	if !ok {
+		pe = &pooledEndpoint{state: BreakerClosed}
+		d.endpoints[address] = pe
+	}
+	return pe
+}
+
+func (d *pooledDriver) notify(address string) func(BreakerState) {
+	if d.opt.OnBreakerChange == nil {
+		return nil
+	}
+	return func(s BreakerState) { d.opt.OnBreakerChange(address, s) }
+}
+
+// Open returns a handle for ep. It never dials immediately; the
+// pooledEndpoint's real session is opened lazily on first use and shared
+// across every handle for the same address.
+func (d *pooledDriver) Open(ctx context.Context, ep Endpoint) (Session, error) {
+	return &pooledSession{driver: d, pe: d.endpointFor(ep.Address), ep: ep}, nil
+}
+
+// CleanUp closes and forgets pooled sessions idle for longer than
+// opt.IdleTimeout. Callers poll it on a ticker the same way
+// ConnectionPool.CleanUp is driven by Manager.StartCleanup.
+func (d *pooledDriver) CleanUp() {
+	d.mu.Lock()
+	cutoff := time.Now().Add(-d.opt.idleTimeout())
+	stale := make([]string, 0)
+	for addr, pe := range d.endpoints {
+		if pe.expired(cutoff) {
+			stale = append(stale, addr)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, addr := range stale {
+		d.mu.Lock()
+		pe, ok := d.endpoints[addr]
+		d.mu.Unlock()
+		if !ok {
+			continue
+		}
+		pe.mu.Lock()
+		if pe.lastUsed.Before(cutoff) {
+			pe.closeSessionLocked()
+		}
+		pe.mu.Unlock()
+	}
+}
+
+// pooledSession is the per-Open handle returned to callers. Close marks the
+// shared endpoint idle rather than tearing down its real session, so a
+// short poll loop (Open, a few calls, Close) doesn't pay reconnect cost
+// next cycle; the real session is only closed by CleanUp or a tripped
+// breaker.
+type pooledSession struct {
+	driver *pooledDriver
+	pe     *pooledEndpoint
+	ep     Endpoint
+}
+
+func (s *pooledSession) Close() error {
+	s.pe.mu.Lock()
+	s.pe.lastUsed = time.Now()
+	s.pe.mu.Unlock()
+	return nil
+}
+
+// pooledCall acquires s's shared real session under the circuit breaker,
+// runs fn against it, and records the outcome.
+func pooledCall[T any](ctx context.Context, s *pooledSession, fn func(Session) (T, error)) (T, error) {
+	var zero T
+	notify := s.driver.notify(s.ep.Address)
+
+	sess, err := s.pe.acquire(ctx, func(ctx context.Context) (Session, error) {
+		return s.driver.base.Open(ctx, s.ep)
+	}, s.driver.opt, notify)
+	if err != nil {
+		return zero, err
+	}
+
+	result, err := fn(sess)
+
+	s.pe.mu.Lock()
+	s.pe.recordLocked(err, s.driver.opt, notify)
+	s.pe.mu.Unlock()
+
+	return result, err
+}
+
+// isConnectionError reports whether err is a MultiMinerError carrying
+// ErrCodeConnectionFailed, i.e. built with NewConnectionError. Only these
+// count towards a pooled endpoint's circuit breaker.
+func isConnectionError(err error) bool {
+	mErr, ok := IsMultiMinerError(err)
+	return ok && mErr.Code == ErrCodeConnectionFailed
+}
+
+func (s *pooledSession) Model(ctx context.Context) (Model, error) {
+	return pooledCall(ctx, s, func(sess Session) (Model, error) { return sess.Model(ctx) })
+}
+
+func (s *pooledSession) Stats(ctx context.Context) (Stats, error) {
+	return pooledCall(ctx, s, func(sess Session) (Stats, error) { return sess.Stats(ctx) })
+}
+
+func (s *pooledSession) Summary(ctx context.Context) (Summary, error) {
+	return pooledCall(ctx, s, func(sess Session) (Summary, error) { return sess.Summary(ctx) })
+}
+
+func (s *pooledSession) Pools(ctx context.Context) ([]Pool, error) {
+	return pooledCall(ctx, s, func(sess Session) ([]Pool, error) { return sess.Pools(ctx) })
+}
+
+func (s *pooledSession) AddPool(ctx context.Context, url, user, pass string) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.AddPool(ctx, url, user, pass) })
+	return err
+}
+
+func (s *pooledSession) EnablePool(ctx context.Context, poolID int64) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.EnablePool(ctx, poolID) })
+	return err
+}
+
+func (s *pooledSession) DisablePool(ctx context.Context, poolID int64) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.DisablePool(ctx, poolID) })
+	return err
+}
+
+func (s *pooledSession) RemovePool(ctx context.Context, poolID int64) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.RemovePool(ctx, poolID) })
+	return err
+}
+
+func (s *pooledSession) SwitchPool(ctx context.Context, poolID int64) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.SwitchPool(ctx, poolID) })
+	return err
+}
+
+func (s *pooledSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.UpdatePool(ctx, poolID, tryUpdate) })
+	return err
+}
+
+func (s *pooledSession) Restart(ctx context.Context) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.Restart(ctx) })
+	return err
+}
+
+func (s *pooledSession) Quit(ctx context.Context) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.Quit(ctx) })
+	return err
+}
+
+func (s *pooledSession) Exec(ctx context.Context, command string, parameter string) ([]byte, error) {
+	return pooledCall(ctx, s, func(sess Session) ([]byte, error) { return sess.Exec(ctx, command, parameter) })
+}
+
+func (s *pooledSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
+	return pooledCall(ctx, s, func(sess Session) (PowerMode, error) { return sess.GetPowerMode(ctx) })
+}
+
+func (s *pooledSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.SetPowerMode(ctx, mode) })
+	return err
+}
+
+func (s *pooledSession) GetFan(ctx context.Context) (FanConfig, error) {
+	return pooledCall(ctx, s, func(sess Session) (FanConfig, error) { return sess.GetFan(ctx) })
+}
+
+func (s *pooledSession) SetFan(ctx context.Context, fan FanConfig) error {
+	_, err := pooledCall(ctx, s, func(sess Session) (struct{}, error) { return struct{}{}, sess.SetFan(ctx, fan) })
+	return err
+}
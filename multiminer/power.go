@@ -0,0 +1,96 @@
+package multiminer
+
+import "context"
+
+// ApplyPowerMode validates the requested PowerMode against the device's
+// driver capabilities, fills in per-chain defaults from the device's current
+// PowerMode for any fields the caller left zero, and applies it.
+//
+// It returns an *UnsupportedTuningError if the driver's capabilities can't
+// honor fields the caller set (e.g. per-chain tuning on a driver that
+// doesn't support it).
+func (m *Manager) ApplyPowerMode(ctx context.Context, id MinerID, mode PowerMode) error {
+	m.mu.RLock()
+	dev := m.dev[id]
+	m.mu.RUnlock()
+	if dev == nil || dev.Driver == nil {
+		return ErrNotFound
+	}
+
+	if err := validatePowerModeCapabilities(dev.Driver.Capabilities(), mode); err != nil {
+		return err
+	}
+
+	return m.WithSession(ctx, id, func(sess Session) error {
+		if current, err := sess.GetPowerMode(ctx); err == nil {
+			mode = fillPowerModeDefaults(mode, current)
+		}
+		return sess.SetPowerMode(ctx, mode)
+	})
+}
+
+func validatePowerModeCapabilities(cap Capability, mode PowerMode) error {
+	var rejected []string
+
+	if len(mode.Chains) > 0 && !cap.SupportsPerChainTuning {
+		rejected = append(rejected, "chains")
+	}
+	if cap.MaxChains > 0 && len(mode.Chains) > cap.MaxChains {
+		rejected = append(rejected, "chains")
+	}
+	if !cap.PowerControl && (mode.Watts > 0 || mode.VoltageMv > 0 || mode.FreqMHz > 0 || len(mode.Chains) > 0) {
+		rejected = append(rejected, "power_control")
+	}
+
+	if len(cap.SupportedPowerModes) > 0 && mode.Kind != "" {
+		supported := false
+		for _, k := range cap.SupportedPowerModes {
+			if k == mode.Kind {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			rejected = append(rejected, "kind")
+		}
+	}
+
+	if len(rejected) > 0 {
+		return &UnsupportedTuningError{Fields: rejected}
+	}
+	return nil
+}
+
+// fillPowerModeDefaults fills zero-valued fields in requested from current,
+// matching Chains entries by Index.
+func fillPowerModeDefaults(requested, current PowerMode) PowerMode {
+	if requested.Watts == 0 {
+		requested.Watts = current.Watts
+	}
+	if requested.VoltageMv == 0 {
+		requested.VoltageMv = current.VoltageMv
+	}
+	if requested.FreqMHz == 0 {
+		requested.FreqMHz = current.FreqMHz
+	}
+
+	for i, chain := range requested.Chains {
+		if chain.FreqMHz != 0 && chain.VoltageMv != 0 {
+			continue
+		}
+		for _, cur := range current.Chains {
+			if cur.Index != chain.Index {
+				continue
+			}
+			if chain.FreqMHz == 0 {
+				requested.Chains[i].FreqMHz = cur.FreqMHz
+			}
+			if chain.VoltageMv == 0 {
+				requested.Chains[i].VoltageMv = cur.VoltageMv
+			}
+			break
+		}
+	}
+
+	return requested
+}
@@ -3,8 +3,16 @@ package multiminer
 import (
 	"context"
 	"encoding/json"
+	"io"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-systemd/activation"
 )
 
 // Server exposes a minimal REST API to manage multiple miners.
@@ -14,14 +22,84 @@ type Server struct {
 	mgr              *Manager
 	addressValidator *AddressValidator
 	commandValidator *CommandValidator
+	ws               *wsManager
+	auth             *AuthMiddleware
+	cfgMgr           *ConfigManager
+	metrics          *Metrics
+
+	bulkMu            sync.RWMutex
+	bulkMaxConcurrent int
 }
 
 func NewServer(mgr *Manager) *Server {
+	// No options are passed here, so NewAddressValidator can't fail.
+	addressValidator, _ := NewAddressValidator()
 	return &Server{
 		mgr:              mgr,
-		addressValidator: NewAddressValidator(),
+		addressValidator: addressValidator,
 		commandValidator: NewCommandValidator(),
+		ws:               newWSManager(),
+		auth:             NewAuthMiddleware(AuthConfig{}),
+	}
+}
+
+// SetAuthConfig reloads the server's authentication config at runtime;
+// in-flight requests finish under the old config, subsequent ones use the new.
+func (s *Server) SetAuthConfig(cfg AuthConfig) {
+	s.auth.SetConfig(cfg)
+}
+
+// UseMetrics wires m's Prometheus endpoint into the server and starts
+// recording HTTP request/exec/validator counters into it.
+func (s *Server) UseMetrics(m *Metrics) {
+	s.metrics = m
+}
+
+// recordExec increments multiminer_exec_total for command/result, a no-op
+// when metrics aren't configured.
+func (s *Server) recordExec(command, result string) {
+	if s.metrics == nil {
+		return
 	}
+	s.metrics.ExecTotal.WithLabelValues(command, result).Inc()
+}
+
+// recordRejected increments multiminer_validator_rejected_total for kind, a
+// no-op when metrics aren't configured.
+func (s *Server) recordRejected(kind string) {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.ValidatorRejected.WithLabelValues(kind).Inc()
+}
+
+// UseConfigManager wires cm's admin routes into the server and subscribes
+// the address/command validators and auth middleware so config reloads take
+// effect immediately, without a restart.
+func (s *Server) UseConfigManager(cm *ConfigManager) error {
+	s.cfgMgr = cm
+	if err := cm.Subscribe(func(cfg *Config) error {
+		s.addressValidator.SetAllowedPorts(cfg.Security.AllowedPorts)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := cm.Subscribe(func(cfg *Config) error {
+		s.commandValidator.SetAllowedCommands(cfg.Security.AllowedCommands)
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := cm.Subscribe(func(cfg *Config) error {
+		s.auth.SetConfig(cfg.Security.Auth)
+		return nil
+	}); err != nil {
+		return err
+	}
+	return cm.Subscribe(func(cfg *Config) error {
+		s.SetBulkConcurrency(cfg.Server.MaxConcurrentBulk)
+		return nil
+	})
 }
 
 func (s *Server) routes(mux *http.ServeMux) {
@@ -53,6 +131,7 @@ func (s *Server) handleDevices(w http.ResponseWriter, r *http.Request) {
 
 		// Validate address for security
 		if err := s.addressValidator.ValidateAddress(req.Address); err != nil {
+			s.recordRejected("address")
 			writeMultiMinerError(w, err.(*MultiMinerError))
 			return
 		}
@@ -99,6 +178,7 @@ func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 				return err
 			}
 			writeJSON(w, http.StatusOK, sm)
+			s.ws.broadcast(wsEvent{Type: "summary", MinerID: string(id), Data: sm})
 			return nil
 		})
 		if err != nil {
@@ -111,11 +191,14 @@ func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 				return err
 			}
 			writeJSON(w, http.StatusOK, st)
+			s.ws.broadcast(wsEvent{Type: "stats", MinerID: string(id), Data: st})
 			return nil
 		})
 		if err != nil {
 			writeErrJSON(w, http.StatusBadGateway, err.Error())
 		}
+	case "GET events":
+		s.handleDeviceEvents(w, r, id)
 	case "POST exec":
 		var req struct {
 			Command   string `json:"command"`
@@ -128,11 +211,13 @@ func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 
 		// Validate command for security
 		if err := s.commandValidator.ValidateCommand(req.Command); err != nil {
+			s.recordRejected("command")
 			writeMultiMinerError(w, err.(*MultiMinerError))
 			return
 		}
 
-		if err := s.commandValidator.ValidateParameter(req.Command, req.Parameter); err != nil {
+		if _, err := s.commandValidator.ValidateParameter(req.Command, req.Parameter); err != nil {
+			s.recordRejected("parameter")
 			writeMultiMinerError(w, err.(*MultiMinerError))
 			return
 		}
@@ -144,10 +229,14 @@ func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 			_, _ = w.Write(data)
+			s.ws.broadcast(wsEvent{Type: "exec_result", MinerID: string(id), Data: json.RawMessage(data)})
 			return nil
 		})
 		if err != nil {
+			s.recordExec(req.Command, "error")
 			writeErrJSON(w, http.StatusBadGateway, err.Error())
+		} else {
+			s.recordExec(req.Command, "success")
 		}
 	case "GET power":
 		err := s.mgr.WithSession(ctx, id, func(sess Session) error {
@@ -174,6 +263,7 @@ func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 			writeErrJSON(w, http.StatusNotImplemented, err.Error())
 		} else {
 			w.WriteHeader(http.StatusNoContent)
+			s.ws.broadcast(wsEvent{Type: "power_mode", MinerID: string(id), Data: req})
 		}
 	case "GET fan":
 		err := s.mgr.WithSession(ctx, id, func(sess Session) error {
@@ -211,11 +301,111 @@ func (s *Server) handleDevice(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		writeJSON(w, http.StatusOK, dev.Driver.Capabilities())
+	case "GET conditions":
+		writeJSON(w, http.StatusOK, s.mgr.Conditions(id))
+	case "POST breaker-reset":
+		s.mgr.ResetBreaker(id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeErrJSON(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleConfigAdmin serves Caddy-style admin routes for the effective config:
+//
+//	GET   /config/                 -> current config
+//	POST  /config/                 -> full replacement
+//	PATCH /config/{path}           -> set a single field, addressed by its JSON tags
+//	POST  /config/revert/{id}      -> roll back to a prior history entry
+func (s *Server) handleConfigAdmin(w http.ResponseWriter, r *http.Request) {
+	if s.cfgMgr == nil {
+		writeErrJSON(w, http.StatusNotImplemented, "config admin is not enabled")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/config"), "/")
+
+	switch {
+	case path == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.cfgMgr.Current())
+	case path == "" && r.Method == http.MethodPost:
+		var candidate Config
+		if err := json.NewDecoder(r.Body).Decode(&candidate); err != nil {
+			writeMultiMinerError(w, NewInvalidInputError("invalid json"))
+			return
+		}
+		if err := s.cfgMgr.Replace(&candidate); err != nil {
+			writeConfigAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.cfgMgr.Current())
+	case path == "history" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, s.cfgMgr.History())
+	case strings.HasPrefix(path, "revert/") && r.Method == http.MethodPost:
+		idStr := strings.TrimPrefix(path, "revert/")
+		id, err := strconv.Atoi(idStr)
+		if err != nil {
+			writeMultiMinerError(w, NewInvalidInputError("invalid history id"))
+			return
+		}
+		if err := s.cfgMgr.Revert(id); err != nil {
+			writeConfigAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.cfgMgr.Current())
+	case path != "" && r.Method == http.MethodPatch:
+		value, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeMultiMinerError(w, NewInvalidInputError("failed to read request body"))
+			return
+		}
+		if err := s.cfgMgr.PatchPath(path, value); err != nil {
+			writeConfigAdminError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.cfgMgr.Current())
 	default:
 		writeErrJSON(w, http.StatusNotFound, "not found")
 	}
 }
 
+// statusRecorder wraps a ResponseWriter to capture the status code written,
+// since http.ResponseWriter doesn't expose it after the fact.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// instrument records request latency into s.metrics.HTTPRequestDuration,
+// labeled by method, path, and status. It's a no-op passthrough when metrics
+// aren't configured.
+func (s *Server) instrument(path string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.metrics == nil {
+			next(w, r)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next(rec, r)
+		s.metrics.HTTPRequestDuration.WithLabelValues(r.Method, path, strconv.Itoa(rec.status)).Observe(time.Since(start).Seconds())
+	}
+}
+
+func writeConfigAdminError(w http.ResponseWriter, err error) {
+	if mErr, ok := IsMultiMinerError(err); ok {
+		writeMultiMinerError(w, mErr)
+		return
+	}
+	writeErrJSON(w, http.StatusInternalServerError, err.Error())
+}
+
 func writeJSON(w http.ResponseWriter, code int, v interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(code)
@@ -231,8 +421,18 @@ func writeMultiMinerError(w http.ResponseWriter, err *MultiMinerError) {
 	writeJSON(w, err.HTTPStatus(), err)
 }
 
-// Start starts HTTP server on provided addr and blocks.
+// Start starts HTTP server on provided addr and blocks. It's a thin wrapper
+// around StartWithConfig for callers that don't need a Unix socket.
 func (s *Server) Start(ctx context.Context, addr string) error {
+	return s.StartWithConfig(ctx, ServerConfig{ListenAddress: addr})
+}
+
+// StartWithConfig starts the HTTP server and blocks until every listener has
+// stopped (all of them once ctx is cancelled, or each on its own failure),
+// then returns the first listener error, if any. The same handler is served over every listener returned
+// by s.listeners(cfg): the configured TCP address, an optional Unix domain
+// socket, or file descriptors handed off by systemd socket activation.
+func (s *Server) StartWithConfig(ctx context.Context, cfg ServerConfig) error {
 	mux := http.NewServeMux()
 	s.routes(mux)
 	// Health endpoints
@@ -242,28 +442,109 @@ func (s *Server) Start(ctx context.Context, addr string) error {
 	mux.HandleFunc("/api/v1/healthz", func(w http.ResponseWriter, r *http.Request) {
 		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
 	})
-	srv := &http.Server{Addr: addr, Handler: mux}
+
+	listeners, err := s.listeners(cfg)
+	if err != nil {
+		return err
+	}
+	if len(listeners) == 0 {
+		return NewInvalidInputError("server: no listen address or unix socket configured")
+	}
+
+	srv := &http.Server{Handler: mux}
 	go func() {
 		<-ctx.Done()
 		_ = srv.Shutdown(context.Background())
 	}()
-	return srv.ListenAndServe()
+
+	errCh := make(chan error, len(listeners))
+	var wg sync.WaitGroup
+	for _, l := range listeners {
+		l := l
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := srv.Serve(l); err != nil && err != http.ErrServerClosed {
+				errCh <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listeners builds the set of net.Listener the server should serve on.
+// Systemd socket activation takes priority: if LISTEN_FDS were passed to us,
+// those listeners are used as-is and cfg is ignored. Otherwise it binds the
+// configured TCP address and/or Unix socket path. A Unix listener created
+// here unlinks its own socket file on Close, so no extra cleanup is needed
+// on shutdown; a stale file from a previous, uncleanly-terminated run is
+// removed before binding.
+func (s *Server) listeners(cfg ServerConfig) ([]net.Listener, error) {
+	if fds, err := activation.Listeners(); err == nil && len(fds) > 0 {
+		return fds, nil
+	}
+
+	var out []net.Listener
+	if cfg.ListenAddress != "" {
+		l, err := net.Listen("tcp", cfg.ListenAddress)
+		if err != nil {
+			return nil, NewConnectionError("failed to bind tcp listener", err)
+		}
+		out = append(out, l)
+	}
+	if cfg.UnixSocket != "" {
+		if err := os.Remove(cfg.UnixSocket); err != nil && !os.IsNotExist(err) {
+			return nil, NewConnectionError("failed to remove stale unix socket", err)
+		}
+		l, err := net.Listen("unix", cfg.UnixSocket)
+		if err != nil {
+			return nil, NewConnectionError("failed to bind unix socket", err)
+		}
+		out = append(out, l)
+	}
+	return out, nil
 }
 
 // mountRoutes mounts REST routes under prefix ("" or "/api/v1").
 func (s *Server) mountRoutes(mux *http.ServeMux, prefix string) {
 	base := strings.TrimSuffix(prefix, "/")
-	mux.HandleFunc(base+"/devices", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc(base+"/devices", s.auth.Wrap(s.instrument("/devices", func(w http.ResponseWriter, r *http.Request) {
 		if base != "" && strings.HasPrefix(r.URL.Path, base) {
 			r.URL.Path = strings.TrimPrefix(r.URL.Path, base)
 		}
 		s.handleDevices(w, r)
-	})
-	mux.HandleFunc(base+"/devices/", func(w http.ResponseWriter, r *http.Request) {
+	})))
+	mux.HandleFunc(base+"/devices/", s.auth.Wrap(s.instrument("/devices/", func(w http.ResponseWriter, r *http.Request) {
 		// Strip version prefix for handler’s path parser
 		if base != "" && strings.HasPrefix(r.URL.Path, base) {
 			r.URL.Path = strings.TrimPrefix(r.URL.Path, base)
 		}
 		s.handleDevice(w, r)
-	})
+	})))
+	mux.HandleFunc(base+"/events", s.auth.Wrap(s.instrument("/events", s.handleClusterEvents)))
+	mux.HandleFunc(base+"/devices/actions", s.auth.Wrap(s.instrument("/devices/actions", s.handleBulkActions)))
+	mux.HandleFunc(base+"/config/", s.auth.Wrap(s.instrument("/config/", func(w http.ResponseWriter, r *http.Request) {
+		if base != "" && strings.HasPrefix(r.URL.Path, base) {
+			r.URL.Path = strings.TrimPrefix(r.URL.Path, base)
+		}
+		s.handleConfigAdmin(w, r)
+	})))
+	// Metrics are intentionally not behind s.auth: Prometheus scrapers don't
+	// carry CSRF cookies, and the endpoint is typically bound to an internal
+	// network rather than exposed the way device control routes are.
+	mux.Handle(base+"/metrics", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.metrics == nil {
+			writeErrJSON(w, http.StatusNotImplemented, "metrics are not enabled")
+			return
+		}
+		s.metrics.Handler().ServeHTTP(w, r)
+	}))
 }
@@ -0,0 +1,347 @@
+package multiminer
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// SessionMetrics holds the Prometheus vectors shared by every
+// InstrumentedSession wrapping a given driver/endpoint. Create one and reuse
+// it across all sessions you want aggregated onto a single farm-wide
+// /metrics endpoint.
+type SessionMetrics struct {
+	registry *prometheus.Registry
+
+	latency *prometheus.HistogramVec
+	errors  *prometheus.CounterVec
+
+	hashrate5s     *prometheus.GaugeVec
+	hashrateAv     *prometheus.GaugeVec
+	tempMax        *prometheus.GaugeVec
+	uptime         *prometheus.GaugeVec
+	hwErrorPercent *prometheus.GaugeVec
+	poolAccepted   *prometheus.GaugeVec
+	poolRejected   *prometheus.GaugeVec
+}
+
+// NewSessionMetrics creates a SessionMetrics with its own registry.
+func NewSessionMetrics() *SessionMetrics {
+	callLabels := []string{"method", "driver", "endpoint"}
+	gaugeLabels := []string{"driver", "endpoint"}
+	poolLabels := []string{"driver", "endpoint", "pool"}
+
+	m := &SessionMetrics{
+		registry: prometheus.NewRegistry(),
+
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "multiminer_session_call_duration_seconds",
+			Help:    "Session method call latency in seconds.",
+			Buckets: prometheus.DefBuckets,
+		}, callLabels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "multiminer_session_call_errors_total",
+			Help: "Session method calls that returned an error, by MultiMinerError code.",
+		}, append(append([]string{}, callLabels...), "code")),
+
+		hashrate5s: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_hashrate_5s_ghs",
+			Help: "5s-window hashrate in GH/s.",
+		}, gaugeLabels),
+		hashrateAv: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_hashrate_avg_ghs",
+			Help: "Average hashrate in GH/s.",
+		}, gaugeLabels),
+		tempMax: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_temp_max_c",
+			Help: "Maximum reported board temperature in Celsius.",
+		}, gaugeLabels),
+		uptime: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_uptime_seconds",
+			Help: "Reported device uptime in seconds.",
+		}, gaugeLabels),
+		hwErrorPercent: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_hw_error_percent",
+			Help: "Device hardware error rate percent.",
+		}, gaugeLabels),
+		poolAccepted: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_pool_accepted",
+			Help: "Accepted shares, labelled by the currently active pool.",
+		}, poolLabels),
+		poolRejected: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "multiminer_pool_rejected",
+			Help: "Rejected shares, labelled by the currently active pool.",
+		}, poolLabels),
+	}
+
+	m.registry.MustRegister(
+		m.latency, m.errors,
+		m.hashrate5s, m.hashrateAv, m.tempMax, m.uptime, m.hwErrorPercent,
+		m.poolAccepted, m.poolRejected,
+	)
+	return m
+}
+
+// Handler serves this SessionMetrics' registry in the Prometheus text
+// exposition format.
+func (m *SessionMetrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// InstrumentedSession wraps a Session, recording call latency and error
+// counts for every method against metrics, and refreshing gauges whenever
+// Stats/Summary/Pools are called.
+type InstrumentedSession struct {
+	Session
+	metrics  *SessionMetrics
+	driver   string
+	endpoint string
+
+	mu         sync.Mutex
+	activePool string
+}
+
+// WrapSession returns sess instrumented under metrics, labelled with driver
+// and endpoint (typically Driver.Name() and Endpoint.Address).
+func WrapSession(sess Session, metrics *SessionMetrics, driver, endpoint string) *InstrumentedSession {
+	return &InstrumentedSession{Session: sess, metrics: metrics, driver: driver, endpoint: endpoint}
+}
+
+// track starts timing method and returns a func to call with the method's
+// error (nil on success) once it returns.
+func (s *InstrumentedSession) track(method string) func(err error) {
+	start := time.Now()
+	return func(err error) {
+		s.metrics.latency.WithLabelValues(method, s.driver, s.endpoint).Observe(time.Since(start).Seconds())
+		if err != nil {
+			code := "unknown"
+			if mErr, ok := IsMultiMinerError(err); ok {
+				code = mErr.Code
+			}
+			s.metrics.errors.WithLabelValues(method, s.driver, s.endpoint, code).Inc()
+		}
+	}
+}
+
+func (s *InstrumentedSession) Close() error {
+	done := s.track("Close")
+	err := s.Session.Close()
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) Model(ctx context.Context) (Model, error) {
+	done := s.track("Model")
+	v, err := s.Session.Model(ctx)
+	done(err)
+	return v, err
+}
+
+func (s *InstrumentedSession) Stats(ctx context.Context) (Stats, error) {
+	done := s.track("Stats")
+	st, err := s.Session.Stats(ctx)
+	done(err)
+	if err == nil {
+		s.metrics.hashrate5s.WithLabelValues(s.driver, s.endpoint).Set(st.Hashrate5s.GHS())
+		s.metrics.hashrateAv.WithLabelValues(s.driver, s.endpoint).Set(st.HashrateAv.GHS())
+		s.metrics.tempMax.WithLabelValues(s.driver, s.endpoint).Set(st.TempMax)
+		s.metrics.uptime.WithLabelValues(s.driver, s.endpoint).Set(float64(st.UptimeSec))
+	}
+	return st, err
+}
+
+func (s *InstrumentedSession) Summary(ctx context.Context) (Summary, error) {
+	done := s.track("Summary")
+	sm, err := s.Session.Summary(ctx)
+	done(err)
+	if err == nil {
+		s.metrics.hwErrorPercent.WithLabelValues(s.driver, s.endpoint).Set(sm.DeviceHardwarePercent)
+
+		s.mu.Lock()
+		pool := s.activePool
+		s.mu.Unlock()
+		s.metrics.poolAccepted.WithLabelValues(s.driver, s.endpoint, pool).Set(float64(sm.Accepted))
+		s.metrics.poolRejected.WithLabelValues(s.driver, s.endpoint, pool).Set(float64(sm.Rejected))
+	}
+	return sm, err
+}
+
+func (s *InstrumentedSession) Pools(ctx context.Context) ([]Pool, error) {
+	done := s.track("Pools")
+	pools, err := s.Session.Pools(ctx)
+	done(err)
+	if err == nil {
+		for _, p := range pools {
+			if p.Active {
+				s.mu.Lock()
+				s.activePool = p.URL
+				s.mu.Unlock()
+				break
+			}
+		}
+	}
+	return pools, err
+}
+
+func (s *InstrumentedSession) AddPool(ctx context.Context, url, user, pass string) error {
+	done := s.track("AddPool")
+	err := s.Session.AddPool(ctx, url, user, pass)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) EnablePool(ctx context.Context, poolID int64) error {
+	done := s.track("EnablePool")
+	err := s.Session.EnablePool(ctx, poolID)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) DisablePool(ctx context.Context, poolID int64) error {
+	done := s.track("DisablePool")
+	err := s.Session.DisablePool(ctx, poolID)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) RemovePool(ctx context.Context, poolID int64) error {
+	done := s.track("RemovePool")
+	err := s.Session.RemovePool(ctx, poolID)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) SwitchPool(ctx context.Context, poolID int64) error {
+	done := s.track("SwitchPool")
+	err := s.Session.SwitchPool(ctx, poolID)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) UpdatePool(ctx context.Context, poolID int64, tryUpdate func(current Pool) (Pool, error)) error {
+	done := s.track("UpdatePool")
+	err := s.Session.UpdatePool(ctx, poolID, tryUpdate)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) Restart(ctx context.Context) error {
+	done := s.track("Restart")
+	err := s.Session.Restart(ctx)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) Quit(ctx context.Context) error {
+	done := s.track("Quit")
+	err := s.Session.Quit(ctx)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) Exec(ctx context.Context, command string, parameter string) ([]byte, error) {
+	done := s.track("Exec")
+	out, err := s.Session.Exec(ctx, command, parameter)
+	done(err)
+	return out, err
+}
+
+func (s *InstrumentedSession) GetPowerMode(ctx context.Context) (PowerMode, error) {
+	done := s.track("GetPowerMode")
+	v, err := s.Session.GetPowerMode(ctx)
+	done(err)
+	return v, err
+}
+
+func (s *InstrumentedSession) SetPowerMode(ctx context.Context, mode PowerMode) error {
+	done := s.track("SetPowerMode")
+	err := s.Session.SetPowerMode(ctx, mode)
+	done(err)
+	return err
+}
+
+func (s *InstrumentedSession) GetFan(ctx context.Context) (FanConfig, error) {
+	done := s.track("GetFan")
+	v, err := s.Session.GetFan(ctx)
+	done(err)
+	return v, err
+}
+
+func (s *InstrumentedSession) SetFan(ctx context.Context, fan FanConfig) error {
+	done := s.track("SetFan")
+	err := s.Session.SetFan(ctx, fan)
+	done(err)
+	return err
+}
+
+// CollectorTarget is one endpoint a SessionCollector polls on a schedule.
+type CollectorTarget struct {
+	Driver   Driver
+	Endpoint Endpoint
+}
+
+// SessionCollector periodically opens a session to each of a fixed set of
+// targets and refreshes their gauges, so a single /metrics endpoint can
+// cover a farm without anything else polling devices on Prometheus's behalf.
+type SessionCollector struct {
+	metrics  *SessionMetrics
+	interval time.Duration
+}
+
+// NewSessionCollector creates a SessionCollector publishing to metrics, at
+// interval <= 0 using defaultScrapeTTL.
+func NewSessionCollector(metrics *SessionMetrics, interval time.Duration) *SessionCollector {
+	if interval <= 0 {
+		interval = defaultScrapeTTL
+	}
+	return &SessionCollector{metrics: metrics, interval: interval}
+}
+
+// Start polls targets immediately and then every interval until ctx is
+// done. It returns immediately; polling happens in the background.
+func (c *SessionCollector) Start(ctx context.Context, targets []CollectorTarget) {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		c.pollAll(ctx, targets)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.pollAll(ctx, targets)
+			}
+		}
+	}()
+}
+
+func (c *SessionCollector) pollAll(ctx context.Context, targets []CollectorTarget) {
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		t := t
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.pollOne(ctx, t)
+		}()
+	}
+	wg.Wait()
+}
+
+func (c *SessionCollector) pollOne(ctx context.Context, t CollectorTarget) {
+	sess, err := t.Driver.Open(ctx, t.Endpoint)
+	if err != nil {
+		return
+	}
+	defer sess.Close()
+
+	inst := WrapSession(sess, c.metrics, t.Driver.Name(), t.Endpoint.Address)
+	_, _ = inst.Pools(ctx)
+	_, _ = inst.Stats(ctx)
+	_, _ = inst.Summary(ctx)
+}
@@ -0,0 +1,186 @@
+package multiminer
+
+import (
+	"context"
+	"crypto/tls"
+	"math/rand"
+	"net"
+	"net/http"
+	"time"
+)
+
+// SessionOptions configures how an HTTP-speaking Session reaches its
+// device: client/dial/TLS timeouts, and a retry policy applied around each
+// request. The zero value preserves the behavior drivers had before this
+// existed: a plain client with a 3s timeout and no retries.
+type SessionOptions struct {
+	// HTTPTimeout bounds each request/response round trip; every retry
+	// gets its own HTTPTimeout. <= 0 uses a 3s default.
+	HTTPTimeout time.Duration
+	// DialTimeout bounds establishing the TCP connection. <= 0 uses a 3s
+	// default.
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout bounds the TLS handshake on top of DialTimeout.
+	// <= 0 uses a 3s default.
+	TLSHandshakeTimeout time.Duration
+	// TLSConfig is used as-is for HTTPS endpoints. nil means Go's default
+	// (certificate verification on, no client certs).
+	TLSConfig *tls.Config
+
+	// Retries is how many additional attempts a failed request gets beyond
+	// the first. <= 0 means no retries.
+	Retries int
+	// Backoff is the base delay before the first retry; each subsequent
+	// retry doubles it with up to 50% jitter added. <= 0 uses a 200ms
+	// default.
+	Backoff time.Duration
+
+	// Logger receives one Debug-level event per HTTP attempt (outcome and
+	// duration) and one Warn-level event per retry. nil uses a NoOpLogger,
+	// so sessions built before this field existed stay silent.
+	Logger Logger
+}
+
+func (o SessionOptions) logger() Logger {
+	if o.Logger == nil {
+		return &NoOpLogger{}
+	}
+	return o.Logger
+}
+
+func (o SessionOptions) httpTimeout() time.Duration {
+	if o.HTTPTimeout <= 0 {
+		return 3 * time.Second
+	}
+	return o.HTTPTimeout
+}
+
+func (o SessionOptions) dialTimeout() time.Duration {
+	if o.DialTimeout <= 0 {
+		return 3 * time.Second
+	}
+	return o.DialTimeout
+}
+
+func (o SessionOptions) tlsHandshakeTimeout() time.Duration {
+	if o.TLSHandshakeTimeout <= 0 {
+		return 3 * time.Second
+	}
+	return o.TLSHandshakeTimeout
+}
+
+func (o SessionOptions) backoff() time.Duration {
+	if o.Backoff <= 0 {
+		return 200 * time.Millisecond
+	}
+	return o.Backoff
+}
+
+func (o SessionOptions) retries() int {
+	if o.Retries < 0 {
+		return 0
+	}
+	return o.Retries
+}
+
+// client builds an *http.Client whose Transport applies o's dial/TLS
+// timeouts, shared across every request the session makes so connections
+// actually get reused instead of being rebuilt per call.
+func (o SessionOptions) client() *http.Client {
+	dialer := &net.Dialer{Timeout: o.dialTimeout()}
+	return &http.Client{
+		Timeout: o.httpTimeout(),
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: o.tlsHandshakeTimeout(),
+			TLSClientConfig:     o.TLSConfig,
+		},
+	}
+}
+
+// backoffFor returns the delay before retry attempt n (0-based: the delay
+// before the first retry, after the first failed attempt), with up to 50%
+// jitter added so a fleet of sessions retrying at once doesn't do so in
+// lockstep.
+func (o SessionOptions) backoffFor(n int) time.Duration {
+	base := o.backoff() << uint(n) // exponential: backoff, 2*backoff, 4*backoff, ...
+	jitter := time.Duration(rand.Int63n(int64(base)/2 + 1))
+	return base + jitter
+}
+
+// doWithRetry calls fn until it succeeds, fn returns a non-retryable error,
+// ctx is done, or o's retry budget is exhausted, whichever comes first.
+//
+// The wait between attempts is driven by a single timer that gets Reset for
+// each retry rather than a fresh timer per attempt, and a select against
+// ctx.Done() lets an in-flight wait abort the instant the caller's deadline
+// elapses — the same cancel-channel/reset-timer shape netstack's gonet
+// package uses for its connection deadline timers.
+func doWithRetry(ctx context.Context, o SessionOptions, fn func() error) error {
+	timer := time.NewTimer(0)
+	if !timer.Stop() {
+		<-timer.C
+	}
+	defer timer.Stop()
+
+	var err error
+	for attempt := 0; attempt <= o.retries(); attempt++ {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err = fn()
+		if err == nil || !isRetryableErr(err) || attempt == o.retries() {
+			return err
+		}
+
+		timer.Reset(o.backoffFor(attempt))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+// isRetryableErr reports whether a failed attempt is worth retrying. A
+// MultiMinerError classified Permanent (auth refused, not implemented)
+// won't succeed on retry, so it's returned immediately instead of burning
+// the retry budget; everything else (including plain network errors, which
+// carry no classification) is retried.
+func isRetryableErr(err error) bool {
+	mErr, ok := IsMultiMinerError(err)
+	if !ok {
+		return true
+	}
+	return mErr.Classification != ClassificationPermanent
+}
+
+// doHTTP performs req using client, retrying per opt. req must have no body
+// (or one safe to send unread more than once): each retry reuses req as-is
+// rather than cloning a body reader.
+func doHTTP(ctx context.Context, client *http.Client, req *http.Request, opt SessionOptions) (*http.Response, error) {
+	log := opt.logger().WithFields(F("endpoint", req.URL.Host), F("method", req.Method), F("path", req.URL.Path))
+
+	attempt := 0
+	var resp *http.Response
+	err := doWithRetry(ctx, opt, func() error {
+		if attempt > 0 {
+			log.Warn(ctx, "retrying http request", F("attempt", attempt))
+		}
+		attempt++
+
+		start := time.Now()
+		r, err := client.Do(req)
+		duration := time.Since(start)
+		if err != nil {
+			log.Debug(ctx, "http request failed", F("duration", duration), F("error", err))
+			return NewConnectionError("http request failed", err)
+		}
+		log.Debug(ctx, "http request completed", F("duration", duration), F("status", r.StatusCode))
+		resp = r
+		return nil
+	})
+	return resp, err
+}
@@ -0,0 +1,163 @@
+package multiminer
+
+import (
+	"encoding/binary"
+	"encoding/gob"
+	"io"
+	"time"
+)
+
+// snapshotMagic identifies a multiminer fleet snapshot stream.
+var snapshotMagic = [4]byte{'M', 'M', 'F', 'S'}
+
+// snapshotVersion is bumped whenever an existing FleetSnapshot field's wire
+// meaning changes in a way gob's own field-by-name matching can't absorb. A
+// brand new field doesn't need a bump: an older reader decoding a newer
+// snapshot simply never sees it, and a newer reader decoding an older one
+// leaves it at its zero value. Note this is NOT field preservation - gob
+// matches by name against the decoding struct, so a field the reader's
+// FleetSnapshot doesn't declare is dropped on decode, not carried through
+// for a later re-encode. Renaming or removing a field is exactly the kind
+// of change that needs a version bump.
+const snapshotVersion uint16 = 1
+
+// ErrSnapshotVersion is returned by LoadSnapshot when a snapshot's version
+// header doesn't match snapshotVersion, so a caller that's upgraded across
+// an incompatible format change fails loudly instead of gob-decoding bytes
+// it would silently misinterpret.
+var ErrSnapshotVersion = NewDeviceError("snapshot version mismatch", "snapshot was written by an incompatible version of this package", nil)
+
+// FleetSnapshot is a point-in-time, binary-serializable view of a fleet:
+// every known device plus the most recently observed Stats/Summary/Pool
+// list and connection-pool statistics for each, keyed by MinerID. Callers
+// assemble one (e.g. Manager.Snapshot for registered devices and their pool
+// stats, or a fuller one built by pairing that with a WithSession sweep
+// that also records live Stats/Summary/Pools) and round-trip it through
+// SaveSnapshot/LoadSnapshot across a process restart or binary upgrade, so
+// a large (1000+ device) fleet doesn't need to be rediscovered and
+// re-polled from scratch.
+type FleetSnapshot struct {
+	TakenAt time.Time
+
+	Devices   []DeviceRecord
+	Stats     map[MinerID]Stats
+	Summaries map[MinerID]Summary
+	Pools     map[MinerID][]Pool
+	PoolStats map[MinerID]PoolStats
+}
+
+// SaveSnapshot writes snap to w as a 4-byte magic, a 2-byte version, and a
+// gob-encoded payload - the same magic+version header shape
+// influxdb/meta's MarshalBinary uses for its own metadata store snapshots.
+// The version guards against wire-incompatible changes (see snapshotVersion);
+// it does not give gob the ability to preserve fields a reader doesn't know
+// about, so an old snapshot decoded by a build that has since removed a
+// field loses that field's data for good, it isn't round-tripped.
+func SaveSnapshot(w io.Writer, snap FleetSnapshot) error {
+	if _, err := w.Write(snapshotMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.BigEndian, snapshotVersion); err != nil {
+		return err
+	}
+	return gob.NewEncoder(w).Encode(snap)
+}
+
+// LoadSnapshot reads a snapshot written by SaveSnapshot. It returns
+// ErrSnapshotVersion if the version header doesn't match snapshotVersion,
+// rather than attempting to gob-decode a payload it might misinterpret.
+func LoadSnapshot(r io.Reader) (FleetSnapshot, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return FleetSnapshot{}, NewDeviceError("failed to read snapshot header", "truncated or empty snapshot", err)
+	}
+	if magic != snapshotMagic {
+		return FleetSnapshot{}, NewDeviceError("not a fleet snapshot", "missing magic header", nil)
+	}
+
+	var version uint16
+	if err := binary.Read(r, binary.BigEndian, &version); err != nil {
+		return FleetSnapshot{}, NewDeviceError("failed to read snapshot header", "truncated snapshot", err)
+	}
+	if version != snapshotVersion {
+		return FleetSnapshot{}, ErrSnapshotVersion
+	}
+
+	var snap FleetSnapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return FleetSnapshot{}, NewDeviceError("failed to decode snapshot", "corrupt gob payload", err)
+	}
+	return snap, nil
+}
+
+// SnapshotTo writes a FleetSnapshot covering every device this pool has
+// opened at least one session for: the device's identity and its
+// PoolStats. It doesn't populate Stats/Summary/Pools - ConnectionPool only
+// tracks connections, not the last response a driver call returned - a
+// caller wanting those folds them into a FleetSnapshot itself (e.g. via a
+// WithSession sweep) before calling SaveSnapshot directly.
+func (p *ConnectionPool) SnapshotTo(w io.Writer) error {
+	p.mu.RLock()
+	devices := make([]DeviceRecord, 0, len(p.pools))
+	for id, dp := range p.pools {
+		dp.mu.Lock()
+		devices = append(devices, DeviceRecord{ID: id, Address: dp.device.Endpoint.Address, DriverName: dp.device.DriverName})
+		dp.mu.Unlock()
+	}
+	p.mu.RUnlock()
+
+	snap := FleetSnapshot{
+		TakenAt:   time.Now(),
+		Devices:   devices,
+		PoolStats: p.Stats(),
+	}
+	return SaveSnapshot(w, snap)
+}
+
+// Snapshot assembles a FleetSnapshot of every device m currently tracks
+// plus each one's connection-pool stats. Like SnapshotTo, it leaves
+// Stats/Summary/Pools unset; nothing in Manager caches a device's last
+// driver response between calls.
+func (m *Manager) Snapshot() FleetSnapshot {
+	m.mu.RLock()
+	devices := make([]DeviceRecord, 0, len(m.dev))
+	for id, d := range m.dev {
+		devices = append(devices, DeviceRecord{ID: id, Address: d.Endpoint.Address, DriverName: d.DriverName})
+	}
+	m.mu.RUnlock()
+
+	return FleetSnapshot{
+		TakenAt:   time.Now(),
+		Devices:   devices,
+		PoolStats: m.pool.Stats(),
+	}
+}
+
+// Save writes m.Snapshot() to w.
+func (m *Manager) Save(w io.Writer) error {
+	return SaveSnapshot(w, m.Snapshot())
+}
+
+// Load reads a FleetSnapshot from r and re-registers every device it
+// describes whose driver m's Registry still recognizes, the same way
+// loadFromStore replays a PersistentStore on startup - so a manager can
+// resume a hibernated fleet without rediscovering it. Devices naming an
+// unknown driver are skipped without a log line (loadFromStore also skips
+// them, but logs a warning).
+func (m *Manager) Load(r io.Reader) error {
+	snap, err := LoadSnapshot(r)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, rec := range snap.Devices {
+		d := m.reg.Get(rec.DriverName)
+		if d == nil {
+			continue
+		}
+		m.dev[rec.ID] = &Device{ID: rec.ID, Endpoint: Endpoint{Address: rec.Address}, Driver: d, DriverName: rec.DriverName}
+	}
+	return nil
+}
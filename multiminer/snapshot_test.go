@@ -0,0 +1,81 @@
+package multiminer
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	snap := FleetSnapshot{
+		Devices: []DeviceRecord{
+			{ID: "dev-1", Address: "192.168.1.100:4028", DriverName: "test-driver"},
+		},
+		PoolStats: map[MinerID]PoolStats{
+			"dev-1": {ActiveConnections: 2},
+		},
+	}
+
+	if err := SaveSnapshot(&buf, snap); err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+
+	got, err := LoadSnapshot(&buf)
+	if err != nil {
+		t.Fatalf("LoadSnapshot: %v", err)
+	}
+
+	if len(got.Devices) != 1 || got.Devices[0].ID != "dev-1" {
+		t.Errorf("unexpected devices: %+v", got.Devices)
+	}
+	if got.PoolStats["dev-1"].ActiveConnections != 2 {
+		t.Errorf("expected ActiveConnections 2, got %+v", got.PoolStats["dev-1"])
+	}
+}
+
+func TestLoadSnapshotRejectsVersionMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	if err := SaveSnapshot(&buf, FleetSnapshot{}); err != nil {
+		t.Fatalf("SaveSnapshot: %v", err)
+	}
+	raw := buf.Bytes()
+	raw[5] = 0xff // corrupt the low byte of the version header
+
+	_, err := LoadSnapshot(bytes.NewReader(raw))
+	if err != ErrSnapshotVersion {
+		t.Errorf("expected ErrSnapshotVersion, got %v", err)
+	}
+}
+
+func TestManagerSaveLoadRestoresDevices(t *testing.T) {
+	reg := NewRegistry()
+	driver := &mockDriver{name: "test-driver", shouldDetect: true}
+	reg.Register(driver)
+
+	mgr := NewManager(reg)
+	defer mgr.Close()
+
+	ctx := context.Background()
+	id := MinerID("test-device")
+	if err := mgr.AddOrDetect(ctx, id, Endpoint{Address: "192.168.1.100:4028"}, driver); err != nil {
+		t.Fatalf("AddOrDetect: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := mgr.Save(&buf); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	restored := NewManager(reg)
+	defer restored.Close()
+
+	if err := restored.Load(&buf); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	devices := restored.List()
+	if len(devices) != 1 || devices[0].ID != id {
+		t.Errorf("expected restored device %q, got %+v", id, devices)
+	}
+}
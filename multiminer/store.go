@@ -0,0 +1,148 @@
+package multiminer
+
+import (
+	"encoding/json"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// DeviceRecord is the persisted form of a registered Device.
+type DeviceRecord struct {
+	ID         MinerID   `json:"id"`
+	Address    string    `json:"address"`
+	DriverName string    `json:"driver_name"`
+	DetectedAt time.Time `json:"detected_at"`
+}
+
+// GroupRecord is the persisted form of a device Group.
+type GroupRecord struct {
+	Name    string    `json:"name"`
+	Members []MinerID `json:"members"`
+}
+
+// PersistentStore durably tracks registered devices and groups across process restarts.
+type PersistentStore interface {
+	Get(id MinerID) (DeviceRecord, bool, error)
+	Put(rec DeviceRecord) error
+	Delete(id MinerID) error
+	List() ([]DeviceRecord, error)
+
+	PutGroup(rec GroupRecord) error
+	DeleteGroup(name string) error
+	ListGroups() ([]GroupRecord, error)
+
+	Close() error
+}
+
+var devicesBucket = []byte("devices")
+var groupsBucket = []byte("groups")
+
+// BoltStore is the default PersistentStore, backed by an embedded bbolt database.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (creating if necessary) a bbolt database at path and
+// ensures the devices bucket exists.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 1 * time.Second})
+	if err != nil {
+		return nil, NewDeviceError("failed to open state store", path, err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(devicesBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(groupsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, NewDeviceError("failed to initialize state store", path, err)
+	}
+
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(id MinerID) (DeviceRecord, bool, error) {
+	var rec DeviceRecord
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(devicesBucket).Get([]byte(id))
+		if data == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(data, &rec)
+	})
+	if err != nil {
+		return DeviceRecord{}, false, err
+	}
+	return rec, found, nil
+}
+
+func (s *BoltStore) Put(rec DeviceRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(devicesBucket).Put([]byte(rec.ID), data)
+	})
+}
+
+func (s *BoltStore) Delete(id MinerID) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(devicesBucket).Delete([]byte(id))
+	})
+}
+
+func (s *BoltStore) List() ([]DeviceRecord, error) {
+	var out []DeviceRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(devicesBucket).ForEach(func(k, v []byte) error {
+			var rec DeviceRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			out = append(out, rec)
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (s *BoltStore) PutGroup(rec GroupRecord) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(groupsBucket).Put([]byte(rec.Name), data)
+	})
+}
+
+func (s *BoltStore) DeleteGroup(name string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(groupsBucket).Delete([]byte(name))
+	})
+}
+
+func (s *BoltStore) ListGroups() ([]GroupRecord, error) {
+	var out []GroupRecord
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(groupsBucket).ForEach(func(k, v []byte) error {
+			var rec GroupRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			out = append(out, rec)
+			return nil
+		})
+	})
+	return out, err
+}
+
+func (s *BoltStore) Close() error { return s.db.Close() }
@@ -1,115 +1,452 @@
 package multiminer
 
 import (
+	"encoding/json"
+	"fmt"
 	"net"
+	"net/url"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/idna"
+	"gopkg.in/yaml.v3"
 )
 
 // AddressValidator validates device addresses
 type AddressValidator struct {
+	mu           sync.RWMutex
 	allowedPorts []int
 	allowedHosts []string
+
+	// allowedCIDRs/deniedCIDRs and allowedHostnames let operators scope
+	// validateHost to specific management subnets instead of relying on
+	// the hard-coded RFC1918 check in isPrivateIP. deniedCIDRs always
+	// wins; when allowedCIDRs or allowedHostnames is non-empty, a host
+	// must match one of them or it's rejected, regardless of the
+	// private-IP/localhost fallback.
+	//
+	// allowedCIDRs/deniedCIDRs only ever match an IP-literal host - this
+	// validator never performs a DNS lookup, so a hostname is checked
+	// against allowedHostnames only and is never resolved against either
+	// CIDR list. An operator who needs a hostname's resolved address kept
+	// out of a denied subnet must enforce that at the network layer (e.g.
+	// a firewall rule or DNS split-horizon), not via WithDeniedCIDRs.
+	allowedCIDRs     []*net.IPNet
+	deniedCIDRs      []*net.IPNet
+	allowedHostnames []string
+
+	strict bool
+}
+
+// ErrSchemeNotAllowed is returned by ValidateAddress/ValidateAndNormalizeAddress
+// in strict mode when address begins with "http://" or "https://" - a
+// scheme-bearing URL where the caller expects a bare host, e.g. a miner
+// management endpoint that dials a raw socket on 4028. Without strict
+// mode, a pasted web UI URL is otherwise accepted here and only fails as a
+// confusing connection error far from the dialer that couldn't use it.
+var ErrSchemeNotAllowed = NewInvalidInputError("address must not include a scheme; expected a bare host, not a URL")
+
+// Strict puts v into strict mode, where ValidateAddress and
+// ValidateAndNormalizeAddress reject any address beginning with "http://"
+// or "https://" with ErrSchemeNotAllowed instead of accepting it as a URL.
+// It mutates v in place and returns it, so it can be chained directly onto
+// a constructed validator, e.g. `v, _ := NewAddressValidator(); v.Strict()`.
+func (v *AddressValidator) Strict() *AddressValidator {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.strict = true
+	return v
 }
 
-// NewAddressValidator creates a new address validator
-func NewAddressValidator() *AddressValidator {
-	return &AddressValidator{
+// AddressValidatorOption configures an AddressValidator at construction
+// time. Options are applied in order and NewAddressValidator returns the
+// first error encountered, e.g. from an unparsable CIDR block.
+type AddressValidatorOption func(*AddressValidator) error
+
+// NewAddressValidator creates a new address validator, applying opts in
+// order.
+func NewAddressValidator(opts ...AddressValidatorOption) (*AddressValidator, error) {
+	v := &AddressValidator{
 		allowedPorts: []int{4028, 8080, 80, 443, 8000, 8080, 9090, 3000, 4029},
 	}
+	for _, opt := range opts {
+		if err := opt(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
 }
 
-// ValidateAddress validates that an address is safe to connect to
-func (v *AddressValidator) ValidateAddress(address string) error {
-	if address == "" {
-		return NewInvalidInputError("address cannot be empty")
+// WithAllowedCIDRs restricts validateHost to IPs contained in one of cidrs,
+// e.g. "10.10.0.0/16". When combined with WithAllowedHostnames, a host need
+// only match one of the two lists.
+func WithAllowedCIDRs(cidrs []string) AddressValidatorOption {
+	return func(v *AddressValidator) error {
+		nets, err := parseCIDRs(cidrs)
+		if err != nil {
+			return err
+		}
+		v.allowedCIDRs = nets
+		return nil
 	}
+}
 
-	// Handle HTTP URLs
-	if strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://") {
-		return v.validateHTTPAddress(address)
+// WithDeniedCIDRs rejects any host whose IP falls in one of cidrs, checked
+// before the allow-list so a deny entry always wins. It only matches
+// IP-literal hosts; a hostname is never resolved to check it against
+// cidrs, so a DNS name that merely happens to resolve into a denied
+// subnet is not caught here - pair this with WithAllowedHostnames or a
+// network-layer control if that matters for your deployment.
+func WithDeniedCIDRs(cidrs []string) AddressValidatorOption {
+	return func(v *AddressValidator) error {
+		nets, err := parseCIDRs(cidrs)
+		if err != nil {
+			return err
+		}
+		v.deniedCIDRs = nets
+		return nil
 	}
+}
 
-	// Handle host:port format
-	host, portStr, err := net.SplitHostPort(address)
+// WithAllowedHostnames restricts validateHost to hostnames matching one of
+// patterns, each a path.Match glob (e.g. "*.miners.example.com"). Combined
+// with WithAllowedCIDRs, a host need only match one of the two lists.
+func WithAllowedHostnames(patterns []string) AddressValidatorOption {
+	return func(v *AddressValidator) error {
+		for _, p := range patterns {
+			if _, err := path.Match(p, ""); err != nil {
+				return NewInvalidInputError(fmt.Sprintf("invalid hostname pattern %q: %v", p, err))
+			}
+		}
+		v.allowedHostnames = patterns
+		return nil
+	}
+}
+
+func parseCIDRs(cidrs []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			return nil, NewInvalidInputError(fmt.Sprintf("invalid CIDR %q: %v", c, err))
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Address is a parsed, structured device address: either a bare host:port
+// (Scheme == "") or an http(s) URL, optionally carrying a path. It's the
+// common representation ParseAddress produces for both forms, so
+// AddressValidator, config files, and the REST API can share one parser
+// and one serialization instead of each doing their own string surgery.
+type Address struct {
+	Scheme    string // "", "http", or "https"
+	Host      string
+	Port      int
+	IsPortSet bool
+	Path      string
+}
+
+// ParseAddress parses raw into a structured Address. raw is either a bare
+// "host:port", or an "http://"/"https://" URL whose host may itself carry
+// a port. Any other scheme is rejected outright, fixing a prior bug where
+// a string like "foo://bar" was silently treated as valid because the old
+// parser only checked for the literal substring "://".
+func ParseAddress(raw string) (Address, error) {
+	if raw == "" {
+		return Address{}, NewInvalidInputError("address cannot be empty")
+	}
+
+	if idx := strings.Index(raw, "://"); idx >= 0 {
+		scheme := raw[:idx]
+		if scheme != "http" && scheme != "https" {
+			return Address{}, NewInvalidInputError(fmt.Sprintf("unsupported URL scheme %q", scheme))
+		}
+
+		rest := raw[idx+len("://"):]
+		hostport, urlPath := rest, ""
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			hostport, urlPath = rest[:slash], rest[slash:]
+		}
+
+		host, port, isPortSet, err := splitHostPort(hostport)
+		if err != nil {
+			return Address{}, err
+		}
+		return Address{Scheme: scheme, Host: host, Port: port, IsPortSet: isPortSet, Path: urlPath}, nil
+	}
+
+	host, port, isPortSet, err := splitHostPort(raw)
 	if err != nil {
-		return NewInvalidInputError("invalid address format, expected host:port")
+		return Address{}, err
+	}
+	return Address{Host: host, Port: port, IsPortSet: isPortSet}, nil
+}
+
+// splitHostPort splits hostport into a host and, if present, a numeric
+// port. A hostport with no ":" is returned with isPortSet false rather
+// than an error, since a scheme-less Address requires a port but a URL's
+// host doesn't.
+func splitHostPort(hostport string) (host string, port int, isPortSet bool, err error) {
+	if !strings.Contains(hostport, ":") {
+		return hostport, 0, false, nil
 	}
 
-	// Validate host
-	if err := v.validateHost(host); err != nil {
+	h, portStr, splitErr := net.SplitHostPort(hostport)
+	if splitErr != nil {
+		return "", 0, false, NewInvalidInputError("invalid host:port format")
+	}
+	p, convErr := strconv.Atoi(portStr)
+	if convErr != nil {
+		return "", 0, false, NewInvalidInputError("invalid port number")
+	}
+	return h, p, true, nil
+}
+
+// String renders a back into its canonical string form, e.g.
+// Address{Host: "192.168.1.100", Port: 4028, IsPortSet: true}.String() ==
+// "192.168.1.100:4028".
+func (a Address) String() string {
+	host := a.Host
+	if a.IsPortSet {
+		host = net.JoinHostPort(a.Host, strconv.Itoa(a.Port))
+	}
+	if a.Scheme == "" {
+		return host
+	}
+	return a.Scheme + "://" + host + a.Path
+}
+
+// MarshalJSON renders a as its canonical address string, so device
+// inventories and REST API responses carry addresses as plain strings on
+// the wire rather than a nested object.
+func (a Address) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+// UnmarshalJSON parses a JSON string via ParseAddress.
+func (a *Address) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+// MarshalYAML renders a as its canonical address string.
+func (a Address) MarshalYAML() (interface{}, error) {
+	return a.String(), nil
+}
+
+// UnmarshalYAML parses a YAML scalar via ParseAddress.
+func (a *Address) UnmarshalYAML(value *yaml.Node) error {
+	var s string
+	if err := value.Decode(&s); err != nil {
+		return err
+	}
+	parsed, err := ParseAddress(s)
+	if err != nil {
 		return err
 	}
+	*a = parsed
+	return nil
+}
+
+// ValidateAddress validates that an address is safe to connect to.
+func (v *AddressValidator) ValidateAddress(address string) error {
+	_, err := v.validateAddress(address, false)
+	return err
+}
 
-	// Validate port
-	port, err := strconv.Atoi(portStr)
+// ValidateAndNormalizeAddress validates address exactly like ValidateAddress,
+// but also returns it with its hostname replaced by the canonical ASCII
+// (punycode, for internationalized hostnames) form IDNA normalization
+// produced, so downstream dialers connect to one stable representation
+// regardless of how the hostname was typed or cased. IP-based addresses
+// are returned unchanged.
+func (v *AddressValidator) ValidateAndNormalizeAddress(address string) (string, error) {
+	return v.validateAddress(address, true)
+}
+
+// validateAddress is the shared implementation behind ValidateAddress and
+// ValidateAndNormalizeAddress: both parse address the same way via
+// ParseAddress and apply the same host/port rules, differing only in
+// whether the host is IDNA-normalized and whether the normalized address
+// string is rebuilt.
+func (v *AddressValidator) validateAddress(address string, normalize bool) (string, error) {
+	v.mu.RLock()
+	strict := v.strict
+	v.mu.RUnlock()
+	if strict && (strings.HasPrefix(address, "http://") || strings.HasPrefix(address, "https://")) {
+		return "", ErrSchemeNotAllowed
+	}
+
+	addr, err := ParseAddress(address)
 	if err != nil {
-		return NewInvalidInputError("invalid port number")
+		return "", err
 	}
 
-	if !v.isPortAllowed(port) {
-		return NewInvalidInputError("port not in allowed list")
+	normalizedHost, err := v.validateHostNormalized(addr.Host)
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	// A bare host:port (no scheme) always requires an explicit port,
+	// checked against the allow-list. HTTP(S) URLs historically didn't
+	// enforce allowedPorts, since they're often port-less (e.g.
+	// "https://example.com/api").
+	if addr.Scheme == "" {
+		if !addr.IsPortSet {
+			return "", NewInvalidInputError("invalid address format, expected host:port")
+		}
+		if !v.isPortAllowed(addr.Port) {
+			return "", NewInvalidInputError("port not in allowed list")
+		}
+	}
+
+	if !normalize {
+		return "", nil
+	}
+	addr.Host = normalizedHost
+	return addr.String(), nil
 }
 
-// validateHost checks if a host is valid and safe
-func (v *AddressValidator) validateHost(host string) error {
+// validateHostNormalized checks if a host is valid and safe, and returns
+// its canonical form (the IDNA ASCII form for hostnames, unchanged for
+// IPs/localhost) so ValidateAndNormalizeAddress can rebuild a normalized
+// address. Denied CIDRs are checked first and always reject;
+// when an allow-list (CIDRs or hostnames) is configured, a host must match
+// one of its entries or it's rejected outright, otherwise validation falls
+// back to the historical localhost/private-IP/hostname-format rules.
+//
+// The denied/allowed CIDR checks below only run for an IP-literal host.
+// A hostname is never resolved here, so it's checked against
+// allowedHostnames instead - see the allowedCIDRs/deniedCIDRs field doc
+// for why that's a deliberate scoping rather than a gap to fill in later.
+func (v *AddressValidator) validateHostNormalized(host string) (string, error) {
 	if host == "" {
-		return NewInvalidInputError("host cannot be empty")
+		return "", NewInvalidInputError("host cannot be empty")
+	}
+
+	v.mu.RLock()
+	hasAllowList := len(v.allowedCIDRs) > 0 || len(v.allowedHostnames) > 0
+	v.mu.RUnlock()
+
+	// Denied CIDRs and a configured allow-list apply to localhost too, so
+	// they're checked before the localhost shortcut below.
+	localIP := net.ParseIP(host)
+	if localIP != nil && v.isDeniedCIDR(localIP) {
+		return "", NewInvalidInputError("host is in a denied CIDR range")
+	}
+	if hasAllowList && isLocalhost(host) {
+		if (localIP != nil && v.isAllowedCIDR(localIP)) || v.isAllowedHostname(host) {
+			return host, nil
+		}
+		return "", NewInvalidInputError("host is not in the allowed CIDR/hostname list")
 	}
 
 	// Check for localhost variations (could be security risk in some environments)
 	if isLocalhost(host) {
-		return nil // Allow localhost for development
+		return host, nil // Allow localhost for development
 	}
 
 	// Validate IP addresses
 	if ip := net.ParseIP(host); ip != nil {
+		if v.isDeniedCIDR(ip) {
+			return "", NewInvalidInputError("host is in a denied CIDR range")
+		}
+		if hasAllowList {
+			if v.isAllowedCIDR(ip) {
+				return host, nil
+			}
+			return "", NewInvalidInputError("host is not in the allowed CIDR/hostname list")
+		}
 		if isPrivateIP(ip) {
-			return nil // Allow private IPs
+			return host, nil // Allow private IPs
 		}
 		// For public IPs, you might want additional validation
-		return nil
+		return host, nil
 	}
 
-	// Validate hostnames
-	if !isValidHostname(host) {
-		return NewInvalidInputError("invalid hostname format")
+	// IDNA-normalize internationalized hostnames to their canonical ASCII
+	// (punycode) form before validating format, so a Unicode label and its
+	// ASCII equivalent are treated identically.
+	ascii, err := toASCIIHostname(host)
+	if err != nil {
+		return "", err
 	}
 
-	return nil
+	if !isValidHostname(ascii) {
+		return "", NewInvalidInputError("invalid hostname format")
+	}
+
+	if hasAllowList {
+		if !v.isAllowedHostname(ascii) && !v.isAllowedHostname(host) {
+			return "", NewInvalidInputError("host is not in the allowed CIDR/hostname list")
+		}
+	}
+
+	return ascii, nil
 }
 
-// validateHTTPAddress validates HTTP/HTTPS URLs
-func (v *AddressValidator) validateHTTPAddress(address string) error {
-	// Basic URL validation - in production, you'd want more sophisticated validation
-	if !strings.Contains(address, "://") {
-		return NewInvalidInputError("invalid HTTP URL format")
+// toASCIIHostname runs hostname through IDNA 2008 normalization, returning
+// its canonical ASCII/punycode form. Plain ASCII hostnames pass through
+// essentially unchanged.
+func toASCIIHostname(hostname string) (string, error) {
+	ascii, err := idna.Lookup.ToASCII(hostname)
+	if err != nil {
+		return "", NewInvalidInputError("invalid internationalized hostname")
 	}
+	return ascii, nil
+}
 
-	// Extract host from URL for additional validation
-	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(address, "http://"), "https://"), "/")
-	if len(parts) == 0 {
-		return NewInvalidInputError("cannot parse host from URL")
+func (v *AddressValidator) isDeniedCIDR(ip net.IP) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, n := range v.deniedCIDRs {
+		if n.Contains(ip) {
+			return true
+		}
 	}
+	return false
+}
 
-	host := parts[0]
-	if strings.Contains(host, ":") {
-		hostPart, _, err := net.SplitHostPort(host)
-		if err != nil {
-			return NewInvalidInputError("invalid URL host:port format")
+func (v *AddressValidator) isAllowedCIDR(ip net.IP) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, n := range v.allowedCIDRs {
+		if n.Contains(ip) {
+			return true
 		}
-		host = hostPart
 	}
+	return false
+}
 
-	return v.validateHost(host)
+func (v *AddressValidator) isAllowedHostname(host string) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	for _, pattern := range v.allowedHostnames {
+		if ok, _ := path.Match(pattern, host); ok {
+			return true
+		}
+	}
+	return false
 }
 
 // isPortAllowed checks if a port is in the allowed list
 func (v *AddressValidator) isPortAllowed(port int) bool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	for _, allowedPort := range v.allowedPorts {
 		if port == allowedPort {
 			return true
@@ -118,6 +455,14 @@ func (v *AddressValidator) isPortAllowed(port int) bool {
 	return false
 }
 
+// SetAllowedPorts replaces the allowed port list, taking effect immediately
+// for subsequent validations. Used to apply config reloads at runtime.
+func (v *AddressValidator) SetAllowedPorts(ports []int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.allowedPorts = ports
+}
+
 // isLocalhost checks if the host is a localhost variant
 func isLocalhost(host string) bool {
 	localhosts := []string{"localhost", "127.0.0.1", "::1", "0.0.0.0"}
@@ -146,19 +491,86 @@ func isPrivateIP(ip net.IP) bool {
 }
 
 // isValidHostname validates hostname format
+// hostnameLabelRegex validates a single dot-separated label of a hostname:
+// alphanumeric and internal dashes only, never leading or trailing with
+// one - tighter than the bare `^[a-zA-Z0-9.-]+$` this replaced, which let
+// through bare dots (empty labels) and "-foo"/"foo-" labels.
+var hostnameLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?$`)
+
+// isValidHostname validates hostname format. hostname is expected to
+// already be in ASCII/punycode form (see toASCIIHostname).
 func isValidHostname(hostname string) bool {
 	if len(hostname) == 0 || len(hostname) > 253 {
 		return false
 	}
 
-	// Simple hostname validation - allows alphanumeric and dashes
-	hostnameRegex := regexp.MustCompile(`^[a-zA-Z0-9.-]+$`)
-	return hostnameRegex.MatchString(hostname)
+	for _, label := range strings.Split(hostname, ".") {
+		if len(label) == 0 || len(label) > 63 {
+			return false
+		}
+		if !hostnameLabelRegex.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParamType identifies how ValidateParameter parses and validates a single
+// field of a command's parameter string.
+type ParamType string
+
+const (
+	ParamTypeURL        ParamType = "url"
+	ParamTypePoolURL    ParamType = "poolURL"
+	ParamTypeEnum       ParamType = "enum"
+	ParamTypeInt        ParamType = "int"
+	ParamTypeBool       ParamType = "bool"
+	ParamTypeDuration   ParamType = "duration"
+	ParamTypeIdentifier ParamType = "identifier"
+)
+
+// ParamField describes one positional field of a command's comma-separated
+// parameter string.
+type ParamField struct {
+	Name string
+	Type ParamType
+
+	// Min/Max are inclusive bounds for ParamTypeInt, enforced only when the
+	// matching HasMin/HasMax is set. Without a HasMin/HasMax flag, Min: 0 and
+	// Max: 0 would be indistinguishable from "no bound configured", which
+	// would let a schema meaning "reject negative values" silently accept them.
+	Min    int64
+	Max    int64
+	HasMin bool
+	HasMax bool
+
+	// Enum lists the allowed values for ParamTypeEnum, matched case-insensitively.
+	Enum []string
+}
+
+// ParamSchema declares the positional fields a command's parameter string
+// must contain. A schema with no Fields means the command takes no
+// parameters.
+type ParamSchema struct {
+	Fields []ParamField
+}
+
+// Param is one field parsed out of a command's parameter string per its
+// ParamSchema. Only the member matching Type is meaningful.
+type Param struct {
+	Name     string
+	Type     ParamType
+	Raw      string
+	Int      int64
+	Bool     bool
+	Duration time.Duration
 }
 
 // CommandValidator validates miner commands for security
 type CommandValidator struct {
+	mu              sync.RWMutex
 	allowedCommands map[string]bool
+	schemas         map[string]ParamSchema
 }
 
 // NewCommandValidator creates a new command validator
@@ -182,7 +594,29 @@ func NewCommandValidator() *CommandValidator {
 		"temps":       true,
 	}
 
-	return &CommandValidator{allowedCommands: allowed}
+	return &CommandValidator{allowedCommands: allowed, schemas: defaultParamSchemas()}
+}
+
+// defaultParamSchemas returns the built-in ParamSchema for each miner
+// command in NewCommandValidator's allow-list that takes parameters.
+// Commands with no schema here fall back to ValidateParameter's generic
+// dangerous-character check.
+func defaultParamSchemas() map[string]ParamSchema {
+	poolIndex := ParamSchema{Fields: []ParamField{{Name: "pool", Type: ParamTypeInt, Min: 0, HasMin: true}}}
+
+	return map[string]ParamSchema{
+		"addpool": {Fields: []ParamField{
+			{Name: "url", Type: ParamTypePoolURL},
+			{Name: "user", Type: ParamTypeIdentifier},
+			{Name: "pass", Type: ParamTypeIdentifier},
+		}},
+		"switchpool":  poolIndex,
+		"enablepool":  poolIndex,
+		"disablepool": poolIndex,
+		"removepool":  poolIndex,
+		"restart":     {},
+		"quit":        {},
+	}
 }
 
 // ValidateCommand checks if a command is allowed
@@ -191,27 +625,165 @@ func (v *CommandValidator) ValidateCommand(command string) error {
 		return NewInvalidInputError("command cannot be empty")
 	}
 
-	if !v.allowedCommands[strings.ToLower(command)] {
+	v.mu.RLock()
+	allowed := v.allowedCommands[strings.ToLower(command)]
+	v.mu.RUnlock()
+	if !allowed {
 		return NewInvalidInputError("command not allowed")
 	}
 
 	return nil
 }
 
-// ValidateParameter validates command parameters
-func (v *CommandValidator) ValidateParameter(command, parameter string) error {
-	// Basic parameter validation - extend as needed per command
+// SetAllowedCommands replaces the command allow-list, taking effect
+// immediately for subsequent validations. Used to apply config reloads at runtime.
+func (v *CommandValidator) SetAllowedCommands(commands []string) {
+	allowed := make(map[string]bool, len(commands))
+	for _, c := range commands {
+		allowed[strings.ToLower(c)] = true
+	}
+
+	v.mu.Lock()
+	v.allowedCommands = allowed
+	v.mu.Unlock()
+}
+
+// RegisterSchema registers (or replaces) the ParamSchema ValidateParameter
+// uses for command, so callers can plug in vendor-specific commands beyond
+// the built-ins from defaultParamSchemas.
+func (v *CommandValidator) RegisterSchema(command string, schema ParamSchema) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.schemas == nil {
+		v.schemas = make(map[string]ParamSchema)
+	}
+	v.schemas[strings.ToLower(command)] = schema
+}
+
+// ValidateParameter validates command's raw parameter string and, for
+// commands with a registered ParamSchema, parses it into typed Params. For
+// commands without one it falls back to the generic dangerous-character
+// check it has always done, so unrecognized/vendor commands aren't
+// unexpectedly blocked by a schema they were never given.
+func (v *CommandValidator) ValidateParameter(command, parameter string) ([]Param, error) {
 	if len(parameter) > 1000 {
-		return NewInvalidInputError("parameter too long")
+		return nil, NewInvalidInputError("parameter too long")
 	}
 
-	// Prevent command injection
-	dangerousChars := []string{";", "&", "|", "`", "$", "(", ")", "<", ">"}
-	for _, char := range dangerousChars {
-		if strings.Contains(parameter, char) {
-			return NewInvalidInputError("parameter contains dangerous characters")
+	v.mu.RLock()
+	schema, hasSchema := v.schemas[strings.ToLower(command)]
+	v.mu.RUnlock()
+
+	if !hasSchema {
+		dangerousChars := []string{";", "&", "|", "`", "$", "(", ")", "<", ">"}
+		for _, char := range dangerousChars {
+			if strings.Contains(parameter, char) {
+				return nil, NewInvalidInputError("parameter contains dangerous characters")
+			}
 		}
+		return nil, nil
 	}
 
-	return nil
-}
\ No newline at end of file
+	return parseParams(schema, parameter)
+}
+
+// parseParams splits raw on commas and parses each part per the matching
+// ParamField in schema.
+func parseParams(schema ParamSchema, raw string) ([]Param, error) {
+	if len(schema.Fields) == 0 {
+		if strings.TrimSpace(raw) != "" {
+			return nil, NewInvalidInputError("command does not take parameters")
+		}
+		return nil, nil
+	}
+
+	parts := strings.Split(raw, ",")
+	if len(parts) != len(schema.Fields) {
+		return nil, NewInvalidInputError(fmt.Sprintf("expected %d parameter(s), got %d", len(schema.Fields), len(parts)))
+	}
+
+	params := make([]Param, len(schema.Fields))
+	for i, field := range schema.Fields {
+		p, err := parseParamField(field, strings.TrimSpace(parts[i]))
+		if err != nil {
+			return nil, err
+		}
+		params[i] = p
+	}
+	return params, nil
+}
+
+// identifierDangerousChars are the shell metacharacters ParamTypeIdentifier
+// still rejects. Unlike the legacy blanket check, "$" is allowed since
+// passwords legitimately contain it.
+var identifierDangerousChars = []string{";", "&", "|", "`", "(", ")", "<", ">"}
+
+func parseParamField(field ParamField, raw string) (Param, error) {
+	p := Param{Name: field.Name, Type: field.Type, Raw: raw}
+
+	switch field.Type {
+	case ParamTypeURL, ParamTypePoolURL:
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: invalid URL", field.Name))
+		}
+		if field.Type == ParamTypePoolURL {
+			switch u.Scheme {
+			case "stratum+tcp", "stratum2+tcp", "stratum+ssl":
+			default:
+				return Param{}, NewInvalidInputError(fmt.Sprintf("%s: unsupported pool scheme %q", field.Name, u.Scheme))
+			}
+		}
+
+	case ParamTypeEnum:
+		matched := false
+		for _, e := range field.Enum {
+			if strings.EqualFold(e, raw) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: must be one of %v", field.Name, field.Enum))
+		}
+
+	case ParamTypeInt:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: invalid integer", field.Name))
+		}
+		if (field.HasMin && n < field.Min) || (field.HasMax && n > field.Max) {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: must be between %d and %d", field.Name, field.Min, field.Max))
+		}
+		p.Int = n
+
+	case ParamTypeBool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: invalid boolean", field.Name))
+		}
+		p.Bool = b
+
+	case ParamTypeDuration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: invalid duration", field.Name))
+		}
+		p.Duration = d
+
+	case ParamTypeIdentifier:
+		if raw == "" || len(raw) > 255 {
+			return Param{}, NewInvalidInputError(fmt.Sprintf("%s: invalid identifier", field.Name))
+		}
+		for _, c := range identifierDangerousChars {
+			if strings.Contains(raw, c) {
+				return Param{}, NewInvalidInputError(fmt.Sprintf("%s: contains dangerous characters", field.Name))
+			}
+		}
+
+	default:
+		return Param{}, NewInvalidInputError(fmt.Sprintf("%s: unknown parameter type %q", field.Name, field.Type))
+	}
+
+	return p, nil
+}
@@ -1,10 +1,109 @@
 package multiminer
 
-import "testing"
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Address
+	}{
+		{"192.168.1.100:4028", Address{Host: "192.168.1.100", Port: 4028, IsPortSet: true}},
+		{"example.com", Address{Host: "example.com"}},
+		{"http://192.168.1.100", Address{Scheme: "http", Host: "192.168.1.100"}},
+		{"https://example.com:8080/api", Address{Scheme: "https", Host: "example.com", Port: 8080, IsPortSet: true, Path: "/api"}},
+	}
+
+	for _, test := range tests {
+		got, err := ParseAddress(test.raw)
+		if err != nil {
+			t.Errorf("ParseAddress(%q): %v", test.raw, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("ParseAddress(%q) = %+v, want %+v", test.raw, got, test.want)
+		}
+	}
+}
+
+func TestParseAddressRejectsMalformedScheme(t *testing.T) {
+	if _, err := ParseAddress("foo://bar"); err == nil {
+		t.Error("expected an unsupported scheme to be rejected instead of treated as a valid address")
+	}
+}
+
+func TestAddressStringRoundTrip(t *testing.T) {
+	addrs := []string{
+		"192.168.1.100:4028",
+		"example.com",
+		"http://192.168.1.100",
+		"https://example.com:8080/api",
+	}
+
+	for _, raw := range addrs {
+		addr, err := ParseAddress(raw)
+		if err != nil {
+			t.Fatalf("ParseAddress(%q): %v", raw, err)
+		}
+		if addr.String() != raw {
+			t.Errorf("ParseAddress(%q).String() = %q, want %q", raw, addr.String(), raw)
+		}
+	}
+}
+
+func TestAddressJSONRoundTrip(t *testing.T) {
+	addr, err := ParseAddress("192.168.1.100:4028")
+	if err != nil {
+		t.Fatalf("ParseAddress: %v", err)
+	}
+
+	data, err := json.Marshal(addr)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if string(data) != `"192.168.1.100:4028"` {
+		t.Errorf("expected address to marshal as a plain string, got %s", data)
+	}
+
+	var got Address
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != addr {
+		t.Errorf("round-tripped address = %+v, want %+v", got, addr)
+	}
+}
+
+func TestAddressYAMLRoundTrip(t *testing.T) {
+	addr, err := ParseAddress("https://example.com:8080/api")
+	if err != nil {
+		t.Fatalf("ParseAddress: %v", err)
+	}
+
+	data, err := yaml.Marshal(addr)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	var got Address
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if got != addr {
+		t.Errorf("round-tripped address = %+v, want %+v", got, addr)
+	}
+}
 
 func TestAddressValidator(t *testing.T) {
-	validator := NewAddressValidator()
-	
+	validator, err := NewAddressValidator()
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
 	tests := []struct {
 		address string
 		valid   bool
@@ -20,13 +119,13 @@ func TestAddressValidator(t *testing.T) {
 		{"http://192.168.1.100", true, "HTTP URL"},
 		{"https://example.com/api", true, "HTTPS URL"},
 	}
-	
+
 	for _, test := range tests {
 		err := validator.ValidateAddress(test.address)
 		isValid := err == nil
-		
+
 		if isValid != test.valid {
-			t.Errorf("Address %q: expected valid=%v, got valid=%v (%s)", 
+			t.Errorf("Address %q: expected valid=%v, got valid=%v (%s)",
 				test.address, test.valid, isValid, test.desc)
 			if err != nil {
 				t.Logf("Error: %v", err)
@@ -35,19 +134,136 @@ func TestAddressValidator(t *testing.T) {
 	}
 }
 
+func TestAddressValidatorAcceptsInternationalizedHostname(t *testing.T) {
+	validator, err := NewAddressValidator()
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	if err := validator.ValidateAddress("xn--mnchen-3ya.example.com:8080"); err != nil {
+		t.Errorf("expected a pre-encoded punycode hostname to validate, got %v", err)
+	}
+}
+
+func TestValidateAndNormalizeAddressReturnsPunycodeForm(t *testing.T) {
+	validator, err := NewAddressValidator()
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	got, err := validator.ValidateAndNormalizeAddress("münchen.example.com:8080")
+	if err != nil {
+		t.Fatalf("ValidateAndNormalizeAddress: %v", err)
+	}
+	if got != "xn--mnchen-3ya.example.com:8080" {
+		t.Errorf("expected normalized punycode host, got %q", got)
+	}
+}
+
+func TestValidateAndNormalizeAddressRejectsLeadingDash(t *testing.T) {
+	validator, err := NewAddressValidator()
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	if _, err := validator.ValidateAndNormalizeAddress("-bad.example.com:8080"); err == nil {
+		t.Error("expected a label starting with a dash to be rejected")
+	}
+	if _, err := validator.ValidateAndNormalizeAddress("bad..example.com:8080"); err == nil {
+		t.Error("expected a bare double-dot (empty label) to be rejected")
+	}
+}
+
+func TestAddressValidatorStrictRejectsSchemePrefix(t *testing.T) {
+	validator, err := NewAddressValidator()
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+	validator.Strict()
+
+	if err := validator.ValidateAddress("http://192.168.1.100:4028"); err != ErrSchemeNotAllowed {
+		t.Errorf("expected ErrSchemeNotAllowed, got %v", err)
+	}
+	if err := validator.ValidateAddress("192.168.1.100:4028"); err != nil {
+		t.Errorf("expected a bare host:port to still validate in strict mode, got %v", err)
+	}
+}
+
+func TestAddressValidatorNonStrictStillAcceptsSchemePrefix(t *testing.T) {
+	validator, err := NewAddressValidator()
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	if err := validator.ValidateAddress("http://192.168.1.100"); err != nil {
+		t.Errorf("expected non-strict validator to still accept a URL, got %v", err)
+	}
+}
+
+func TestAddressValidatorDeniedCIDRAlwaysRejects(t *testing.T) {
+	validator, err := NewAddressValidator(
+		WithAllowedCIDRs([]string{"0.0.0.0/0"}),
+		WithDeniedCIDRs([]string{"192.168.50.0/24"}),
+	)
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	if err := validator.ValidateAddress("192.168.50.5:4028"); err == nil {
+		t.Error("expected denied CIDR to reject even though it's within the allow-list")
+	}
+	if err := validator.ValidateAddress("192.168.1.1:4028"); err != nil {
+		t.Errorf("expected non-denied address to validate, got %v", err)
+	}
+}
+
+func TestAddressValidatorAllowListRejectsNonMatching(t *testing.T) {
+	validator, err := NewAddressValidator(WithAllowedCIDRs([]string{"10.10.0.0/16"}))
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	if err := validator.ValidateAddress("10.10.5.5:4028"); err != nil {
+		t.Errorf("expected address within allowed CIDR to validate, got %v", err)
+	}
+	if err := validator.ValidateAddress("192.168.1.1:4028"); err == nil {
+		t.Error("expected address outside the allow-list to be rejected")
+	}
+}
+
+func TestAddressValidatorAllowedHostnameGlob(t *testing.T) {
+	validator, err := NewAddressValidator(WithAllowedHostnames([]string{"*.miners.example.com"}))
+	if err != nil {
+		t.Fatalf("NewAddressValidator: %v", err)
+	}
+
+	if err := validator.ValidateAddress("rig1.miners.example.com:4028"); err != nil {
+		t.Errorf("expected hostname matching the allowed glob to validate, got %v", err)
+	}
+	if err := validator.ValidateAddress("example.com:4028"); err == nil {
+		t.Error("expected hostname not matching the allowed glob to be rejected")
+	}
+}
+
+func TestNewAddressValidatorRejectsInvalidCIDR(t *testing.T) {
+	if _, err := NewAddressValidator(WithAllowedCIDRs([]string{"not-a-cidr"})); err == nil {
+		t.Error("expected an invalid CIDR to be rejected at construction")
+	}
+}
+
 func TestCommandValidator(t *testing.T) {
 	validator := NewCommandValidator()
-	
+
 	validCommands := []string{"version", "summary", "devs", "pools", "stats"}
 	invalidCommands := []string{"", "rm", "shutdown", "delete", "format"}
-	
+
 	for _, cmd := range validCommands {
 		err := validator.ValidateCommand(cmd)
 		if err != nil {
 			t.Errorf("Command %q should be valid, got error: %v", cmd, err)
 		}
 	}
-	
+
 	for _, cmd := range invalidCommands {
 		err := validator.ValidateCommand(cmd)
 		if err == nil {
@@ -58,36 +274,102 @@ func TestCommandValidator(t *testing.T) {
 
 func TestParameterValidator(t *testing.T) {
 	validator := NewCommandValidator()
-	
+
 	validParams := []string{"", "1", "pool.example.com:4242", "user123"}
 	invalidParams := []string{
 		"param;injection",
-		"param&injection", 
+		"param&injection",
 		"param|injection",
 		"param`injection",
 		"param$injection",
 		"param(injection)",
 		"param<injection>",
 	}
-	
+
 	for _, param := range validParams {
-		err := validator.ValidateParameter("version", param)
+		_, err := validator.ValidateParameter("version", param)
 		if err != nil {
 			t.Errorf("Parameter %q should be valid, got error: %v", param, err)
 		}
 	}
-	
+
 	for _, param := range invalidParams {
-		err := validator.ValidateParameter("version", param)
+		_, err := validator.ValidateParameter("version", param)
 		if err == nil {
 			t.Errorf("Parameter %q should be invalid", param)
 		}
 	}
-	
+
 	// Test parameter length limit
 	longParam := string(make([]byte, 1001))
-	err := validator.ValidateParameter("version", longParam)
+	_, err := validator.ValidateParameter("version", longParam)
 	if err == nil {
 		t.Error("Long parameter should be invalid")
 	}
-}
\ No newline at end of file
+}
+
+func TestParameterValidatorAddpoolSchema(t *testing.T) {
+	validator := NewCommandValidator()
+
+	params, err := validator.ValidateParameter("addpool", "stratum+tcp://pool.example.com:3333,worker1,p$ssw0rd")
+	if err != nil {
+		t.Fatalf("expected valid addpool params, got error: %v", err)
+	}
+	if len(params) != 3 || params[2].Raw != "p$ssw0rd" {
+		t.Errorf("expected password param to preserve '$', got %+v", params)
+	}
+
+	if _, err := validator.ValidateParameter("addpool", "not-a-url,worker1,pass"); err == nil {
+		t.Error("expected a malformed pool URL to be rejected")
+	}
+	if _, err := validator.ValidateParameter("addpool", "stratum+tcp://pool.example.com:3333,worker1"); err == nil {
+		t.Error("expected a missing field to be rejected")
+	}
+}
+
+func TestParameterValidatorSwitchpoolSchema(t *testing.T) {
+	validator := NewCommandValidator()
+
+	params, err := validator.ValidateParameter("switchpool", "2")
+	if err != nil {
+		t.Fatalf("expected valid switchpool param, got error: %v", err)
+	}
+	if len(params) != 1 || params[0].Int != 2 {
+		t.Errorf("expected parsed pool index 2, got %+v", params)
+	}
+
+	if _, err := validator.ValidateParameter("switchpool", "not-a-number"); err == nil {
+		t.Error("expected a non-numeric pool index to be rejected")
+	}
+	if _, err := validator.ValidateParameter("switchpool", "-1"); err == nil {
+		t.Error("expected a negative pool index to be rejected")
+	}
+	if _, err := validator.ValidateParameter("removepool", "-5"); err == nil {
+		t.Error("expected a negative pool index to be rejected")
+	}
+}
+
+func TestParameterValidatorNoArgsSchema(t *testing.T) {
+	validator := NewCommandValidator()
+
+	if _, err := validator.ValidateParameter("restart", ""); err != nil {
+		t.Errorf("expected empty restart params to be valid, got %v", err)
+	}
+	if _, err := validator.ValidateParameter("restart", "unexpected"); err == nil {
+		t.Error("expected restart to reject an unexpected parameter")
+	}
+}
+
+func TestRegisterSchemaOverridesBuiltin(t *testing.T) {
+	validator := NewCommandValidator()
+	validator.RegisterSchema("switchpool", ParamSchema{Fields: []ParamField{
+		{Name: "pool", Type: ParamTypeEnum, Enum: []string{"primary", "backup"}},
+	}})
+
+	if _, err := validator.ValidateParameter("switchpool", "primary"); err != nil {
+		t.Errorf("expected overridden schema to accept enum value, got %v", err)
+	}
+	if _, err := validator.ValidateParameter("switchpool", "1"); err == nil {
+		t.Error("expected overridden schema to reject the old int-based value")
+	}
+}
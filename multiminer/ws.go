@@ -0,0 +1,287 @@
+package multiminer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsSendBuffer    = 64
+	wsPingInterval  = 30 * time.Second
+	wsWriteWaitTime = 10 * time.Second
+)
+
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// Dashboards are typically served from elsewhere; leave origin checking
+	// to a reverse proxy / API gateway in front of this library's Server.
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// wsEvent is a JSON message pushed to subscribed WebSocket clients.
+type wsEvent struct {
+	Type    string      `json:"type"` // "stats", "summary", "power_mode", "exec_result", "error"
+	MinerID string      `json:"miner_id,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+	Time    time.Time   `json:"time"`
+}
+
+// wsFilter narrows which events a client receives. Empty sets mean "all".
+type wsFilter struct {
+	minerIDs map[string]bool
+	metrics  map[string]bool
+}
+
+func (f wsFilter) matches(ev wsEvent) bool {
+	if len(f.minerIDs) > 0 && !f.minerIDs[ev.MinerID] {
+		return false
+	}
+	if len(f.metrics) > 0 && !f.metrics[ev.Type] {
+		return false
+	}
+	return true
+}
+
+// wsControlMessage is a client->server message: either a subscription
+// filter update or an exec request issued over the same socket.
+type wsControlMessage struct {
+	Type      string   `json:"type"` // "subscribe" or "exec"
+	MinerIDs  []string `json:"miner_ids,omitempty"`
+	Metrics   []string `json:"metrics,omitempty"`
+	MinerID   string   `json:"miner_id,omitempty"`
+	Command   string   `json:"command,omitempty"`
+	Parameter string   `json:"parameter,omitempty"`
+}
+
+// wsManager fans Server-side events out to subscribed WebSocket clients.
+type wsManager struct {
+	mu      sync.RWMutex
+	clients map[*wsClient]struct{}
+}
+
+func newWSManager() *wsManager {
+	return &wsManager{clients: make(map[*wsClient]struct{})}
+}
+
+func (m *wsManager) register(c *wsClient) {
+	m.mu.Lock()
+	m.clients[c] = struct{}{}
+	m.mu.Unlock()
+}
+
+func (m *wsManager) unregister(c *wsClient) {
+	m.mu.Lock()
+	delete(m.clients, c)
+	m.mu.Unlock()
+}
+
+// broadcast delivers ev to every client whose filter matches it.
+func (m *wsManager) broadcast(ev wsEvent) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for c := range m.clients {
+		if c.wants(ev) {
+			c.push(data)
+		}
+	}
+}
+
+// wsClient wraps a single WebSocket connection with a bounded, drop-oldest
+// outbound buffer so one slow dashboard can't back up the rest.
+type wsClient struct {
+	srv  *Server
+	conn *websocket.Conn
+	send chan []byte
+
+	mu     sync.Mutex
+	filter wsFilter
+}
+
+func newWSClient(srv *Server, conn *websocket.Conn) *wsClient {
+	return &wsClient{srv: srv, conn: conn, send: make(chan []byte, wsSendBuffer)}
+}
+
+func (c *wsClient) wants(ev wsEvent) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.filter.matches(ev)
+}
+
+// push enqueues data for delivery, dropping the oldest buffered message
+// first if the client's channel is already full.
+func (c *wsClient) push(data []byte) {
+	select {
+	case c.send <- data:
+		return
+	default:
+	}
+
+	select {
+	case <-c.send:
+	default:
+	}
+	select {
+	case c.send <- data:
+	default:
+	}
+}
+
+func (c *wsClient) setFilter(ids, metrics []string) {
+	f := wsFilter{}
+	if len(ids) > 0 {
+		f.minerIDs = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			f.minerIDs[id] = true
+		}
+	}
+	if len(metrics) > 0 {
+		f.metrics = make(map[string]bool, len(metrics))
+		for _, m := range metrics {
+			f.metrics[m] = true
+		}
+	}
+
+	c.mu.Lock()
+	c.filter = f
+	c.mu.Unlock()
+}
+
+// run drives the client's read and write pumps until the connection closes.
+func (c *wsClient) run() {
+	done := make(chan struct{})
+	go c.writePump(done)
+	c.readPump()
+	close(done)
+	c.conn.Close()
+}
+
+func (c *wsClient) readPump() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var msg wsControlMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.push(mustMarshalEvent(wsEvent{Type: "error", Data: "invalid control message"}))
+			continue
+		}
+
+		switch msg.Type {
+		case "subscribe":
+			c.setFilter(msg.MinerIDs, msg.Metrics)
+		case "exec":
+			c.handleExec(msg)
+		default:
+			c.push(mustMarshalEvent(wsEvent{Type: "error", Data: "unknown control message type"}))
+		}
+	}
+}
+
+func (c *wsClient) handleExec(msg wsControlMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := c.srv.commandValidator.ValidateCommand(msg.Command); err != nil {
+		c.push(mustMarshalEvent(wsEvent{Type: "error", MinerID: msg.MinerID, Data: err.Error()}))
+		return
+	}
+	if _, err := c.srv.commandValidator.ValidateParameter(msg.Command, msg.Parameter); err != nil {
+		c.push(mustMarshalEvent(wsEvent{Type: "error", MinerID: msg.MinerID, Data: err.Error()}))
+		return
+	}
+
+	var result []byte
+	err := c.srv.mgr.WithSession(ctx, MinerID(msg.MinerID), func(sess Session) error {
+		data, err := sess.Exec(ctx, msg.Command, msg.Parameter)
+		result = data
+		return err
+	})
+
+	ev := wsEvent{Type: "exec_result", MinerID: msg.MinerID}
+	if err != nil {
+		ev.Type = "error"
+		ev.Data = err.Error()
+	} else {
+		ev.Data = json.RawMessage(result)
+	}
+	c.push(mustMarshalEvent(ev))
+
+	c.srv.ws.broadcast(ev)
+}
+
+func (c *wsClient) writePump(done chan struct{}) {
+	ticker := time.NewTicker(wsPingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWaitTime))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWaitTime))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func mustMarshalEvent(ev wsEvent) []byte {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	data, _ := json.Marshal(ev)
+	return data
+}
+
+// handleDeviceEvents upgrades the connection and streams events scoped to a
+// single device (the client may still widen its filter via "subscribe").
+func (s *Server) handleDeviceEvents(w http.ResponseWriter, r *http.Request, id MinerID) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := newWSClient(s, conn)
+	c.setFilter([]string{string(id)}, nil)
+	s.ws.register(c)
+	defer s.ws.unregister(c)
+
+	c.run()
+}
+
+// handleClusterEvents upgrades the connection and streams events for every
+// device until the client narrows things down with a "subscribe" message.
+func (s *Server) handleClusterEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := wsUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := newWSClient(s, conn)
+	s.ws.register(c)
+	defer s.ws.unregister(c)
+
+	c.run()
+}